@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+
+	"github.com/ibookerke/choco_parser_go/internal/config"
+	"github.com/ibookerke/choco_parser_go/internal/pkg/report"
+	"github.com/ibookerke/choco_parser_go/internal/repository"
+	"github.com/ibookerke/choco_parser_go/internal/service"
+)
+
+func customersDedupe(ctx context.Context, conf config.Config, customerService *service.CustomerService, args []string) {
+	flags := flag.NewFlagSet("customers dedupe", flag.ContinueOnError)
+	out := addOutputFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return
+	}
+
+	duplicates, err := customerService.FindPhoneDuplicates(ctx)
+	if err != nil {
+		fmt.Println("error finding phone duplicates: ", err)
+		return
+	}
+
+	table := report.NewTable(fmt.Sprintf("%d phones shared by several user IDs", len(duplicates)),
+		report.Column{Name: "phone", Kind: report.Phone},
+		report.Column{Name: "user_ids", Kind: report.UserIDList},
+		report.Column{Name: "sources"},
+	)
+	for _, d := range duplicates {
+		table.Append(d.Phone, d.UserIDs, d.Sources)
+	}
+
+	if err := out.write(conf.Privacy, table); err != nil {
+		fmt.Println("error writing phone duplicates: ", err)
+		return
+	}
+}
+
+func customerShow(ctx context.Context, conf config.Config, profileService *service.CustomerProfileService, args []string) {
+	if len(args) == 0 {
+		fmt.Println("usage: customer show <user_id> [--mask]")
+		return
+	}
+
+	userID, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		fmt.Println("invalid user id: ", args[0])
+		return
+	}
+
+	flags := flag.NewFlagSet("customer show", flag.ContinueOnError)
+	out := addOutputFlags(flags)
+	if err := flags.Parse(args[1:]); err != nil {
+		return
+	}
+
+	overview, err := profileService.GetOverview(ctx, userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		fmt.Println("customer not found: ", userID)
+		return
+	}
+	if err != nil {
+		fmt.Println("error getting customer profile: ", err)
+		return
+	}
+
+	p := overview.Profile
+	profile := report.NewTable("profile",
+		report.Column{Name: "user_id", Kind: report.UserID},
+		report.Column{Name: "full_name", Kind: report.Name},
+		report.Column{Name: "phone", Kind: report.Phone},
+		report.Column{Name: "birthday"},
+		report.Column{Name: "orders"},
+		report.Column{Name: "turnover"},
+		report.Column{Name: "visits"},
+		report.Column{Name: "last_visit"},
+	)
+	profile.Append(p.UserID, p.FullName, p.Phone, p.Birthday, p.OrdersCount, p.Turnover, p.VisitsCount, p.LastVisitDate)
+
+	companies := report.NewTable("companies",
+		report.Column{Name: "company"},
+		report.Column{Name: "turnover"},
+		report.Column{Name: "visits"},
+		report.Column{Name: "average_bill"},
+		report.Column{Name: "last_visit"},
+	)
+	for _, c := range p.Companies {
+		companies.Append(c.Company, c.Turnover, c.VisitsCount, c.AverageBill, c.LastVisitDate)
+	}
+
+	payments := report.NewTable("recent payments",
+		report.Column{Name: "payment_id"},
+		report.Column{Name: "created_at"},
+		report.Column{Name: "type"},
+		report.Column{Name: "amount"},
+		report.Column{Name: "discount"},
+		report.Column{Name: "refunded"},
+		report.Column{Name: "location"},
+	)
+	for _, pm := range overview.RecentPayments {
+		payments.Append(int64(pm.ID), pm.CreatedAt, string(pm.Type), pm.Amount, pm.DiscountAmount, pm.RefundedAmount, pm.LocationTitle)
+	}
+
+	reviews := report.NewTable("recent reviews",
+		report.Column{Name: "payment_id"},
+		report.Column{Name: "created_at"},
+		report.Column{Name: "rating"},
+		report.Column{Name: "comment"},
+	)
+	for _, r := range overview.RecentReviews {
+		reviews.Append(int64(r.PaymentID), r.CreatedAt, r.Rating, r.Comment)
+	}
+
+	if err := out.write(conf.Privacy, profile, companies, payments, reviews); err != nil {
+		fmt.Println("error writing customer profile: ", err)
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ibookerke/choco_parser_go/internal/pkg/audience"
+	"github.com/ibookerke/choco_parser_go/internal/service"
+)
+
+// exportAudience writes the phones of an RFM segment, a saved segment or a filter for the customer
+// match upload of an ad platform. Only hashed phones are written, raw ones never leave through this command.
+func exportAudience(
+	ctx context.Context,
+	segmentService *service.SegmentService,
+	savedSegmentService *service.SavedSegmentService,
+	args []string,
+) {
+	flags := flag.NewFlagSet("export audience", flag.ContinueOnError)
+	segment := flags.String("segment", "", "stored RFM segment, e.g. champions")
+	company := flags.String("company", "", "take the RFM segment of this company only, every company when empty")
+	saved := flags.String("saved", "", "saved segment, see segments save")
+	event := flags.String("event", "", "with --saved, only the customers that entered or left it on its latest evaluation")
+	filter := flags.String("filter", "", `segment filter, e.g. 'turnover > 50000 AND company = "malatang"'`)
+	format := flags.String("format", "hashed", "output format, only hashed is supported")
+	layout := flags.String("layout", "google", "CSV layout: "+strings.Join(audience.Layouts(), ", "))
+	out := &outputFlags{}
+	flags.StringVar(&out.output, "output", "", "write to the file instead of stdout")
+	if err := flags.Parse(args); err != nil {
+		return
+	}
+	if countSet(*segment, *saved, *filter) != 1 {
+		fmt.Println("exactly one of --segment, --saved and --filter is required")
+		return
+	}
+	if *event != "" && *saved == "" {
+		fmt.Println("--event needs --saved")
+		return
+	}
+	if *format != "hashed" {
+		fmt.Println("unknown format " + *format + ", only hashed is supported")
+		return
+	}
+
+	l, err := audience.ParseLayout(*layout)
+	if err != nil {
+		fmt.Println("error exporting audience: ", err)
+		return
+	}
+
+	var phones []string
+	switch {
+	case *event != "":
+		phones, err = savedSegmentService.ChangePhones(ctx, *saved, *event)
+	case *saved != "":
+		phones, err = savedSegmentService.SavedPhones(ctx, *saved, time.Now())
+	case *filter != "":
+		phones, err = savedSegmentService.FilterPhones(ctx, *filter, time.Now())
+	default:
+		phones, err = segmentService.AudiencePhones(ctx, *company, *segment)
+	}
+	if err != nil {
+		fmt.Println("error exporting audience: ", err)
+		return
+	}
+
+	var written int
+	err = out.writeTo(func(w io.Writer) error {
+		written, err = audience.WriteHashed(w, l, phones)
+		return err
+	})
+	if err != nil {
+		fmt.Println("error writing audience: ", err)
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "%d hashed phones written\n", written)
+}
+
+func countSet(values ...string) int {
+	var n int
+	for _, v := range values {
+		if v != "" {
+			n++
+		}
+	}
+
+	return n
+}
@@ -2,16 +2,19 @@ package main
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"log/slog"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/golang-migrate/migrate/v4"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 
 	"github.com/ibookerke/choco_parser_go/internal/config"
+	"github.com/ibookerke/choco_parser_go/internal/migration"
+	"github.com/ibookerke/choco_parser_go/internal/pkg/notify"
 	"github.com/ibookerke/choco_parser_go/internal/pkg/pgx"
 	"github.com/ibookerke/choco_parser_go/internal/pkg/trm/manager"
 	"github.com/ibookerke/choco_parser_go/internal/repository"
@@ -48,22 +51,54 @@ func main() {
 		logger.Error("couldn't create migrate instance", "err", err)
 		return
 	}
-	if err = m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+	if err = migration.Up(ctx, m, pool, logger, migration.All); err != nil {
 		logger.Error("couldn't migrate database", "err", err)
 		return
 	}
 
 	trManager := manager.Must(pgx.NewDefaultFactory(pool))
 
+	notifier, err := newNotifier(conf.Notify)
+	if err != nil {
+		logger.Error("couldn't create notifier", "err", err)
+		return
+	}
+
 	branchRepo := repository.NewBranchRepository(pool, pgx.DefaultCtxGetter, trManager)
 	customerRepo := repository.NewCustomerRepository(pool, pgx.DefaultCtxGetter, trManager)
 	paymentRepo := repository.NewPaymentRepository(pool, pgx.DefaultCtxGetter, trManager)
 	authRepo := repository.NewAuthRepository(pool, pgx.DefaultCtxGetter, trManager)
 	companyCustomerRepo := repository.NewCompanyCustomerRepository(pool, pgx.DefaultCtxGetter, trManager)
+	reviewRepo := repository.NewReviewRepository(pool, pgx.DefaultCtxGetter, trManager)
+	customerProfileRepo := repository.NewCustomerProfileRepository(pool, pgx.DefaultCtxGetter, trManager)
+	privacyRepo := repository.NewPrivacyRepository(pool, pgx.DefaultCtxGetter, trManager)
+	staffRepo := repository.NewStaffRepository(pool, pgx.DefaultCtxGetter, trManager)
+	alertRepo := repository.NewAlertRepository(pool, pgx.DefaultCtxGetter, trManager)
+	forecastRepo := repository.NewForecastRepository(pool, pgx.DefaultCtxGetter, trManager)
+	lifecycleRepo := repository.NewLifecycleRepository(pool, pgx.DefaultCtxGetter, trManager)
+	segmentRepo := repository.NewCustomerSegmentRepository(pool, pgx.DefaultCtxGetter, trManager)
+	savedSegmentRepo := repository.NewSavedSegmentRepository(pool, pgx.DefaultCtxGetter, trManager)
+	reportRepo := repository.NewReportRepository(pool, pgx.DefaultCtxGetter, trManager)
+	revenueRepo := repository.NewRevenueRepository(pool, pgx.DefaultCtxGetter, trManager)
 
 	branchService := service.NewBranchService(branchRepo, authRepo, trManager, conf.Choco)
-	paymentService := service.NewPaymentService(paymentRepo, customerRepo, authRepo, trManager, conf.Choco)
-	companyCustomerService := service.NewCompanyCustomersService(companyCustomerRepo, trManager, conf.Choco)
+	paymentService := service.NewPaymentService(paymentRepo, customerRepo, branchRepo, reviewRepo, privacyRepo, staffRepo, authRepo, trManager, conf.Choco)
+	companyCustomerService := service.NewCompanyCustomersService(companyCustomerRepo, privacyRepo, trManager, conf.Choco)
+	customerService := service.NewCustomerService(customerRepo, authRepo, trManager, conf.Choco)
+	customerProfileService := service.NewCustomerProfileService(customerProfileRepo, paymentRepo, reviewRepo)
+	privacyService := service.NewPrivacyService(privacyRepo, trManager)
+	segmentService := service.NewSegmentService(segmentRepo, branchRepo, trManager)
+	savedSegmentService := service.NewSavedSegmentService(savedSegmentRepo, trManager)
+	reportService := service.NewReportService(reportRepo, branchRepo)
+	revenueService := service.NewRevenueService(revenueRepo, trManager)
+	anomalyService := service.NewAnomalyService(paymentRepo)
+	forecastService := service.NewForecastService(revenueRepo, forecastRepo, trManager)
+	lifecycleService := service.NewLifecycleService(lifecycleRepo, trManager)
+	alertService, err := service.NewAlertService(alertRepo, revenueRepo, notifier, conf.Alerts)
+	if err != nil {
+		logger.Error("couldn't create alert service", "err", err)
+		return
+	}
 
 	if len(os.Args) < 2 {
 		fmt.Println("invalid number of parameters passed")
@@ -79,11 +114,64 @@ func main() {
 
 	switch commandAction {
 	case "customers":
-		fetchCustomers(ctx, branchService, paymentService)
+		if len(os.Args) > 2 && os.Args[2] == "dedupe" {
+			customersDedupe(ctx, conf, customerService, os.Args[3:])
+			break
+		}
+		fetchCustomers(ctx, notifier, branchService, paymentService, revenueService, lifecycleService, alertService)
 		break
+	case "customer":
+		if len(os.Args) < 3 || os.Args[2] != "show" {
+			fmt.Println("usage: customer show <user_id>")
+			break
+		}
+		customerShow(ctx, conf, customerProfileService, os.Args[3:])
+	case "privacy":
+		if len(os.Args) < 3 || os.Args[2] != "erase" {
+			fmt.Println("usage: privacy erase --user-id <user_id>")
+			break
+		}
+		privacyErase(ctx, privacyService, os.Args[3:])
+	case "segments":
+		if len(os.Args) < 3 {
+			fmt.Println("usage: segments rfm|save|eval|list|changes [flags]")
+			break
+		}
+		switch os.Args[2] {
+		case "rfm":
+			segmentsRFM(ctx, conf, segmentService, os.Args[3:])
+		case "save":
+			segmentsSave(ctx, conf, savedSegmentService, os.Args[3:])
+		case "eval":
+			segmentsEval(ctx, conf, savedSegmentService, os.Args[3:])
+		case "list":
+			segmentsList(ctx, conf, savedSegmentService, os.Args[3:])
+		case "changes":
+			segmentsChanges(ctx, conf, savedSegmentService, os.Args[3:])
+		default:
+			fmt.Println("usage: segments rfm|save|eval|list|changes [flags]")
+		}
+	case "report":
+		if len(os.Args) < 3 {
+			fmt.Println("usage: report <name> [flags]")
+			break
+		}
+		runReport(ctx, conf, reports{
+			report:    reportService,
+			revenue:   revenueService,
+			anomaly:   anomalyService,
+			forecast:  forecastService,
+			lifecycle: lifecycleService,
+		}, os.Args[2], os.Args[3:])
+	case "export":
+		if len(os.Args) < 3 || os.Args[2] != "audience" {
+			fmt.Println("usage: export audience --segment <segment>|--saved <name>|--filter <filter> --format hashed")
+			break
+		}
+		exportAudience(ctx, segmentService, savedSegmentService, os.Args[3:])
 	case "company_customers":
 		company_name := os.Args[2]
-		fetchCompanyCustomers(ctx, branchService, companyCustomerService, company_name)
+		fetchCompanyCustomers(ctx, notifier, branchService, companyCustomerService, company_name)
 	default:
 		fmt.Println("invalid command name")
 		break
@@ -93,44 +181,70 @@ func main() {
 
 func fetchCompanyCustomers(
 	ctx context.Context,
+	notifier notify.Notifier,
 	branchService *service.BranchService,
 	companyCustomerService *service.CompanyCustomersService,
 	companyName string,
 ) {
 	_, err := branchService.FetchBranches(ctx)
 	if err != nil {
-		fmt.Println("error fetching terminals: ", err)
+		syncFailed(ctx, notifier, "fetching terminals", err)
 		return
 	}
 
 	terminals, err := branchService.GetBranchTerminals(ctx, companyName)
 	if err != nil {
-		fmt.Println("error fetching terminals: ", err)
+		syncFailed(ctx, notifier, "fetching terminals", err)
 		return
 	}
 
 	err = companyCustomerService.FetchCompanyCustomers(ctx, terminals, companyName)
 	if err != nil {
-		fmt.Println("error fetching company customers: ", err)
+		syncFailed(ctx, notifier, "fetching company customers", err)
 		return
 	}
 }
 
 func fetchCustomers(
 	ctx context.Context,
+	notifier notify.Notifier,
 	branchService *service.BranchService,
 	paymentService *service.PaymentService,
-
+	revenueService *service.RevenueService,
+	lifecycleService *service.LifecycleService,
+	alertService *service.AlertService,
 ) {
 	terminals, err := branchService.FetchBranches(ctx)
 	if err != nil {
-		fmt.Println("error fetching terminals: ", err)
+		syncFailed(ctx, notifier, "fetching terminals", err)
+		return
+	}
+
+	synced, err := paymentService.FetchPayments(ctx, terminals)
+	if err != nil {
+		syncFailed(ctx, notifier, "fetching payments", err)
+		return
+	}
+
+	// the payments are stored already, so the summaries are still refreshed when linking fails
+	if err := paymentService.LinkRefunds(ctx, time.Now()); err != nil {
+		syncFailed(ctx, notifier, "linking refunds", err)
+	}
+
+	if err := revenueService.RefreshDailyRevenue(ctx, synced); err != nil {
+		syncFailed(ctx, notifier, "refreshing daily revenue", err)
 		return
 	}
 
-	err = paymentService.FetchPayments(ctx, terminals)
+	transitions, err := lifecycleService.Update(ctx, time.Now())
 	if err != nil {
-		fmt.Println("error fetching payments: ", err)
+		syncFailed(ctx, notifier, "updating customer lifecycle", err)
+		return
+	}
+	fmt.Println(strconv.Itoa(transitions) + " customer lifecycle transitions")
+
+	if err := alertService.Evaluate(ctx, time.Now()); err != nil {
+		syncFailed(ctx, notifier, "evaluating alert rules", err)
 		return
 	}
 
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ibookerke/choco_parser_go/internal/config"
+	"github.com/ibookerke/choco_parser_go/internal/pkg/notify"
+	"github.com/ibookerke/choco_parser_go/internal/service"
+)
+
+// newNotifier sends through every configured backend, or prints to stdout when none is.
+func newNotifier(cfg config.Notify) (notify.Notifier, error) {
+	var notifiers notify.Multi
+
+	if cfg.WebhookURL != "" {
+		if cfg.WebhookSecret == "" {
+			return nil, errors.New("NOTIFY_WEBHOOK_SECRET is required with NOTIFY_WEBHOOK_URL")
+		}
+		notifiers = append(notifiers, notify.NewWebhook(cfg.WebhookURL, cfg.WebhookSecret))
+	}
+
+	if cfg.SMTPAddr != "" {
+		if cfg.SMTPFrom == "" || len(cfg.SMTPTo) == 0 {
+			return nil, errors.New("NOTIFY_SMTP_FROM and NOTIFY_SMTP_TO are required with NOTIFY_SMTP_ADDR")
+		}
+		notifiers = append(notifiers, notify.NewSMTP(cfg.SMTPAddr, cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPFrom, cfg.SMTPTo))
+	}
+
+	if len(notifiers) == 0 {
+		return notify.NewWriter(os.Stdout), nil
+	}
+
+	return notifiers, nil
+}
+
+// syncFailed prints the error of a sync step and sends it through the notifier.
+func syncFailed(ctx context.Context, notifier notify.Notifier, step string, err error) {
+	fmt.Println("error "+step+": ", err)
+
+	subject := "sync failed: " + step
+	if errors.Is(err, service.ErrTokenRefresh) {
+		subject = "sync failed: access token refresh"
+	}
+
+	notifyErr := notifier.Notify(ctx, notify.Message{Subject: subject, Body: err.Error(), SentAt: time.Now()})
+	if notifyErr != nil {
+		fmt.Println("error sending sync failure notification: ", notifyErr)
+	}
+}
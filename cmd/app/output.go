@@ -0,0 +1,66 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ibookerke/choco_parser_go/internal/config"
+	"github.com/ibookerke/choco_parser_go/internal/pkg/mask"
+	"github.com/ibookerke/choco_parser_go/internal/pkg/report"
+)
+
+// outputFlags are shared by every report and export command.
+type outputFlags struct {
+	format string
+	output string
+	mask   bool
+}
+
+func addOutputFlags(flags *flag.FlagSet) *outputFlags {
+	o := &outputFlags{}
+	flags.StringVar(&o.format, "format", string(report.FormatTable), "output format: table, csv or json")
+	flags.StringVar(&o.output, "output", "", "write to the file instead of stdout")
+	flags.BoolVar(&o.mask, "mask", false, "mask phones and names and pseudonymize user IDs")
+
+	return o
+}
+
+func (o *outputFlags) write(cfg config.Privacy, tables ...*report.Table) error {
+	format, err := report.ParseFormat(o.format)
+	if err != nil {
+		return err
+	}
+
+	var masker *mask.Masker
+	if o.mask {
+		masker, err = mask.New(cfg.PseudonymKey)
+		if err != nil {
+			return fmt.Errorf("masking needs PRIVACY_PSEUDONYM_KEY: %w", err)
+		}
+	}
+
+	return o.writeTo(func(w io.Writer) error {
+		return report.Write(w, format, masker, tables...)
+	})
+}
+
+// writeTo passes the output file, or stdout when there is none, to fn.
+func (o *outputFlags) writeTo(fn func(w io.Writer) error) (err error) {
+	if o.output == "" {
+		return fn(os.Stdout)
+	}
+
+	f, err := os.Create(o.output)
+	if err != nil {
+		return fmt.Errorf("create output file: %w", err)
+	}
+	defer func() {
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	return fn(f)
+}
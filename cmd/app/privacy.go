@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/ibookerke/choco_parser_go/internal/service"
+)
+
+func privacyErase(ctx context.Context, privacyService *service.PrivacyService, args []string) {
+	flags := flag.NewFlagSet("privacy erase", flag.ContinueOnError)
+	userID := flags.Int64("user-id", 0, "Choco user id to erase")
+	if err := flags.Parse(args); err != nil {
+		return
+	}
+	if *userID <= 0 {
+		fmt.Println("--user-id is required")
+		return
+	}
+
+	erasure, err := privacyService.Erase(ctx, *userID)
+	if err != nil {
+		fmt.Println("error erasing user: ", err)
+		return
+	}
+
+	fmt.Println("user erased: ", erasure.UserID)
+	fmt.Println("customers deleted: ", erasure.CustomersDeleted)
+	fmt.Println("company customers deleted: ", erasure.CompanyCustomersDeleted)
+	fmt.Println("reviews deleted: ", erasure.ReviewsDeleted)
+	fmt.Println("payments anonymized: ", erasure.PaymentsAnonymized)
+}
@@ -0,0 +1,685 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ibookerke/choco_parser_go/internal/config"
+	"github.com/ibookerke/choco_parser_go/internal/domain"
+	"github.com/ibookerke/choco_parser_go/internal/pkg/chocotime"
+	"github.com/ibookerke/choco_parser_go/internal/pkg/heatmap"
+	"github.com/ibookerke/choco_parser_go/internal/pkg/report"
+	"github.com/ibookerke/choco_parser_go/internal/service"
+)
+
+// reports are the services the report commands read from.
+type reports struct {
+	report    *service.ReportService
+	revenue   *service.RevenueService
+	anomaly   *service.AnomalyService
+	forecast  *service.ForecastService
+	lifecycle *service.LifecycleService
+}
+
+func runReport(ctx context.Context, conf config.Config, services reports, name string, args []string) {
+	switch name {
+	case "cohorts":
+		reportCohorts(ctx, conf, services.report, args)
+	case "churn":
+		reportChurn(ctx, conf, services.report, args)
+	case "birthdays":
+		reportBirthdays(ctx, conf, services.report, args)
+	case "discounts":
+		reportDiscounts(ctx, conf, services.report, args)
+	case "overlap":
+		reportOverlap(ctx, conf, services.report, args)
+	case "heatmap":
+		reportHeatmap(ctx, conf, services.report, args)
+	case "anomalies":
+		reportAnomalies(ctx, conf, services.anomaly, args)
+	case "staff":
+		reportStaff(ctx, conf, services.report, args)
+	case "forecast":
+		reportForecast(ctx, conf, services.forecast, args)
+	case "lifecycle":
+		reportLifecycle(ctx, conf, services.lifecycle, args)
+	case "revenue":
+		reportRevenue(ctx, conf, services.revenue, args)
+	default:
+		fmt.Println("invalid report name: ", name)
+	}
+}
+
+func reportCohorts(ctx context.Context, conf config.Config, reportService *service.ReportService, args []string) {
+	flags := flag.NewFlagSet("report cohorts", flag.ContinueOnError)
+	company := flags.String("company", "", "company name the terminals are matched by")
+	out := addOutputFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return
+	}
+	if *company == "" {
+		fmt.Println("--company is required")
+		return
+	}
+
+	rows, err := reportService.Cohorts(ctx, *company)
+	if err != nil {
+		fmt.Println("error building cohorts: ", err)
+		return
+	}
+
+	months := 0
+	for _, row := range rows {
+		months = max(months, len(row.Retention))
+	}
+
+	columns := []report.Column{{Name: "cohort"}, {Name: "customers"}}
+	for i := 0; i < months; i++ {
+		columns = append(columns, report.Column{Name: fmt.Sprintf("m%d", i)})
+	}
+
+	table := report.NewTable("retention by first payment month, % of the cohort", columns...)
+	for _, row := range rows {
+		values := []any{fmt.Sprintf("%04d-%02d", row.Cohort.Year, row.Cohort.Month), row.Customers}
+		for i := 0; i < months; i++ {
+			if i < len(row.Retention) {
+				values = append(values, row.Retention[i])
+			} else {
+				values = append(values, nil)
+			}
+		}
+		table.Append(values...)
+	}
+
+	if err := out.write(conf.Privacy, table); err != nil {
+		fmt.Println("error writing cohorts: ", err)
+	}
+}
+
+func reportChurn(ctx context.Context, conf config.Config, reportService *service.ReportService, args []string) {
+	flags := flag.NewFlagSet("report churn", flag.ContinueOnError)
+	company := flags.String("company", "", "company name the terminals are matched by")
+	inactiveDays := flags.Int("inactive-days", 45, "minimum days since the last visit")
+	minTurnover := flags.String("min-turnover", "0", "minimum customer turnover in tenge")
+	out := addOutputFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return
+	}
+	if *company == "" {
+		fmt.Println("--company is required")
+		return
+	}
+
+	turnover, err := domain.ParseMoney(*minTurnover)
+	if err != nil {
+		fmt.Println("invalid --min-turnover: ", err)
+		return
+	}
+
+	risks, err := reportService.ChurnRisks(ctx, *company, *inactiveDays, turnover, time.Now())
+	if err != nil {
+		fmt.Println("error building churn list: ", err)
+		return
+	}
+
+	table := report.NewTable("customers at risk of churn, ranked by expected lost revenue",
+		report.Column{Name: "user_id", Kind: report.UserID},
+		report.Column{Name: "full_name", Kind: report.Name},
+		report.Column{Name: "phone", Kind: report.Phone},
+		report.Column{Name: "turnover"},
+		report.Column{Name: "visits"},
+		report.Column{Name: "average_bill"},
+		report.Column{Name: "last_visit"},
+		report.Column{Name: "gap_days"},
+		report.Column{Name: "typical_interval_days"},
+		report.Column{Name: "interval_source"},
+		report.Column{Name: "expected_loss"},
+	)
+	for _, r := range risks {
+		table.Append(
+			r.UserID, r.FullName, r.Phone, r.Turnover, r.VisitsCount, r.AverageBill,
+			r.LastVisit, r.GapDays, r.TypicalIntervalDays, r.IntervalSource, r.ExpectedLoss,
+		)
+	}
+
+	if err := out.write(conf.Privacy, table); err != nil {
+		fmt.Println("error writing churn list: ", err)
+	}
+}
+
+func reportBirthdays(ctx context.Context, conf config.Config, reportService *service.ReportService, args []string) {
+	flags := flag.NewFlagSet("report birthdays", flag.ContinueOnError)
+	company := flags.String("company", "", "company name as stored in company_customers")
+	days := flags.Int("days", 14, "days to look for birthdays in, today included")
+	out := addOutputFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return
+	}
+	if *company == "" {
+		fmt.Println("--company is required")
+		return
+	}
+
+	birthdays, err := reportService.UpcomingBirthdays(ctx, *company, *days, time.Now())
+	if err != nil {
+		fmt.Println("error building birthdays list: ", err)
+		return
+	}
+
+	table := report.NewTable(fmt.Sprintf("birthdays in the next %d days", *days),
+		report.Column{Name: "phone", Kind: report.Phone},
+		report.Column{Name: "full_name", Kind: report.Name},
+		report.Column{Name: "user_id", Kind: report.UserID},
+		report.Column{Name: "birthday"},
+		report.Column{Name: "days_left"},
+		report.Column{Name: "age"},
+		report.Column{Name: "visits"},
+		report.Column{Name: "turnover"},
+	)
+	for _, b := range birthdays {
+		table.Append(b.Phone, b.FullName, b.UserID, b.NextBirthday, b.DaysLeft, b.Age, b.VisitsCount, b.Turnover)
+	}
+
+	if err := out.write(conf.Privacy, table); err != nil {
+		fmt.Println("error writing birthdays list: ", err)
+	}
+}
+
+// dateRangeFlags are the --from and --to flags, by default the last 30 days.
+type dateRangeFlags struct {
+	from string
+	to   string
+}
+
+func addDateRangeFlags(flags *flag.FlagSet) *dateRangeFlags {
+	now := time.Now().In(chocotime.Location)
+	d := &dateRangeFlags{}
+	flags.StringVar(&d.from, "from", now.AddDate(0, 0, -30).Format("2006-01-02"), "first day, YYYY-MM-DD")
+	flags.StringVar(&d.to, "to", now.Format("2006-01-02"), "last day, YYYY-MM-DD")
+
+	return d
+}
+
+func (d *dateRangeFlags) dates() (domain.Date, domain.Date, error) {
+	from, err := parseDateFlag(d.from)
+	if err != nil {
+		return domain.Date{}, domain.Date{}, fmt.Errorf("invalid --from: %w", err)
+	}
+
+	to, err := parseDateFlag(d.to)
+	if err != nil {
+		return domain.Date{}, domain.Date{}, fmt.Errorf("invalid --to: %w", err)
+	}
+
+	if to.Before(from) {
+		return domain.Date{}, domain.Date{}, fmt.Errorf("--to %s is before --from %s", to, from)
+	}
+
+	return from, to, nil
+}
+
+func parseDateFlag(s string) (domain.Date, error) {
+	y, m, d, err := chocotime.ParseDate(s)
+	if err != nil {
+		return domain.Date{}, err
+	}
+
+	return domain.NewDate(y, m, d), nil
+}
+
+func reportRevenue(ctx context.Context, conf config.Config, revenueService *service.RevenueService, args []string) {
+	flags := flag.NewFlagSet("report revenue", flag.ContinueOnError)
+	dates := addDateRangeFlags(flags)
+	groupBy := flags.String("group-by", domain.GroupByDay, "period: day, week or month")
+	out := addOutputFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return
+	}
+
+	from, to, err := dates.dates()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	rows, err := revenueService.Revenue(ctx, from, to, *groupBy)
+	if err != nil {
+		fmt.Println("error building revenue report: ", err)
+		return
+	}
+
+	table := report.NewTable(fmt.Sprintf("revenue from %s to %s by %s", from, to, *groupBy),
+		report.Column{Name: "period"},
+		report.Column{Name: "partner"},
+		report.Column{Name: "location"},
+		report.Column{Name: "location_id"},
+		report.Column{Name: "payments"},
+		report.Column{Name: "gross"},
+		report.Column{Name: "discounts"},
+		report.Column{Name: "refunds"},
+		report.Column{Name: "net"},
+	)
+	for _, r := range rows {
+		table.Append(r.Period, r.PartnerName, r.Location, r.LocationID, r.Payments, r.Gross, r.Discounts, r.RefundAmount, r.Net)
+	}
+
+	if err := out.write(conf.Privacy, table); err != nil {
+		fmt.Println("error writing revenue report: ", err)
+	}
+}
+
+func reportDiscounts(ctx context.Context, conf config.Config, reportService *service.ReportService, args []string) {
+	flags := flag.NewFlagSet("report discounts", flag.ContinueOnError)
+	dates := addDateRangeFlags(flags)
+	returnDays := flags.Int("return-days", 30, "days a customer has to pay again to count as returned")
+	out := addOutputFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return
+	}
+
+	from, to, err := dates.dates()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	rows, err := reportService.Discounts(ctx, from, to, *returnDays)
+	if err != nil {
+		fmt.Println("error building discounts report: ", err)
+		return
+	}
+
+	table := report.NewTable(fmt.Sprintf("discounts from %s to %s, returns within %d days", from, to, *returnDays),
+		report.Column{Name: "location"},
+		report.Column{Name: "location_id"},
+		report.Column{Name: "month"},
+		report.Column{Name: "payments"},
+		report.Column{Name: "discounted_payments"},
+		report.Column{Name: "discounts"},
+		report.Column{Name: "discount_share_pct"},
+		report.Column{Name: "avg_discount"},
+		report.Column{Name: "avg_given_discount"},
+		report.Column{Name: "discounted_customers"},
+		report.Column{Name: "discounted_return_pct"},
+		report.Column{Name: "other_customers"},
+		report.Column{Name: "other_return_pct"},
+	)
+	for _, r := range rows {
+		table.Append(
+			r.Location,
+			r.LocationID,
+			fmt.Sprintf("%04d-%02d", r.Month.Year, r.Month.Month),
+			r.Payments,
+			r.DiscountedPayments,
+			r.Discounts,
+			r.DiscountShare,
+			r.AverageDiscount,
+			r.AverageGivenDiscount,
+			r.DiscountedCustomers,
+			r.DiscountedReturnRate,
+			r.OtherCustomers,
+			r.OtherReturnRate,
+		)
+	}
+
+	if err := out.write(conf.Privacy, table); err != nil {
+		fmt.Println("error writing discounts report: ", err)
+	}
+}
+
+// stringsFlag collects the values of a flag given several times.
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringsFlag) Set(v string) error {
+	*f = append(*f, v)
+	return nil
+}
+
+func reportOverlap(ctx context.Context, conf config.Config, reportService *service.ReportService, args []string) {
+	flags := flag.NewFlagSet("report overlap", flag.ContinueOnError)
+	var companies stringsFlag
+	flags.Var(&companies, "company", "company to compare, given twice")
+	out := addOutputFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return
+	}
+	if len(companies) != 2 {
+		fmt.Println("--company must be given exactly twice")
+		return
+	}
+
+	overlap, err := reportService.Overlap(ctx, companies[0], companies[1])
+	if err != nil {
+		fmt.Println("error building overlap report: ", err)
+		return
+	}
+
+	summary := report.NewTable("overlap",
+		report.Column{Name: "company_a"},
+		report.Column{Name: "company_b"},
+		report.Column{Name: "shared_customers"},
+		report.Column{Name: "turnover_a"},
+		report.Column{Name: "turnover_b"},
+		report.Column{Name: "combined_turnover"},
+		report.Column{Name: "switchers"},
+	)
+	summary.Append(
+		overlap.CompanyA,
+		overlap.CompanyB,
+		len(overlap.Customers),
+		overlap.TurnoverA,
+		overlap.TurnoverB,
+		overlap.TurnoverA+overlap.TurnoverB,
+		len(overlap.Switchers),
+	)
+
+	shared := report.NewTable("shared customers",
+		report.Column{Name: "phone", Kind: report.Phone},
+		report.Column{Name: "full_name", Kind: report.Name},
+		report.Column{Name: "user_id", Kind: report.UserID},
+		report.Column{Name: "turnover_a"},
+		report.Column{Name: "turnover_b"},
+		report.Column{Name: "visits_a"},
+		report.Column{Name: "visits_b"},
+		report.Column{Name: "last_visit_a"},
+		report.Column{Name: "last_visit_b"},
+	)
+	for _, c := range overlap.Customers {
+		shared.Append(c.Phone, c.FullName, c.UserID, c.TurnoverA, c.TurnoverB, c.VisitsA, c.VisitsB, c.LastVisitA, c.LastVisitB)
+	}
+
+	switchers := report.NewTable("switchers",
+		report.Column{Name: "phone", Kind: report.Phone},
+		report.Column{Name: "full_name", Kind: report.Name},
+		report.Column{Name: "user_id", Kind: report.UserID},
+		report.Column{Name: "from"},
+		report.Column{Name: "to"},
+		report.Column{Name: "prev_last_visit"},
+		report.Column{Name: "last_visit"},
+	)
+	for _, s := range overlap.Switchers {
+		switchers.Append(s.Phone, s.FullName, s.UserID, s.From, s.To, s.PrevLastVisit, s.LastVisit)
+	}
+
+	if err := out.write(conf.Privacy, summary, shared, switchers); err != nil {
+		fmt.Println("error writing overlap report: ", err)
+	}
+}
+
+func reportHeatmap(ctx context.Context, conf config.Config, reportService *service.ReportService, args []string) {
+	flags := flag.NewFlagSet("report heatmap", flag.ContinueOnError)
+	location := flags.String("location", "", "location ID or a part of its title")
+	metric := flags.String("metric", "payments", "value drawn in svg format: payments or revenue")
+	dates := addDateRangeFlags(flags)
+	out := addOutputFlags(flags)
+	flags.Lookup("format").Usage = "output format: table, csv, json or svg"
+	if err := flags.Parse(args); err != nil {
+		return
+	}
+	if *location == "" {
+		fmt.Println("--location is required")
+		return
+	}
+
+	from, to, err := dates.dates()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	hm, err := reportService.Heatmap(ctx, *location, from, to)
+	if err != nil {
+		fmt.Println("error building heatmap: ", err)
+		return
+	}
+
+	title := fmt.Sprintf("%s %s from %s to %s", *location, *metric, from, to)
+
+	if strings.EqualFold(out.format, "svg") {
+		var (
+			grid   heatmap.Grid
+			format func(float64) string
+		)
+		switch *metric {
+		case "payments":
+			for d := range hm.Payments {
+				for h, v := range hm.Payments[d] {
+					grid[d][h] = float64(v)
+				}
+			}
+			format = func(v float64) string { return strconv.FormatFloat(v, 'f', 0, 64) }
+		case "revenue":
+			for d := range hm.Revenue {
+				for h, v := range hm.Revenue[d] {
+					grid[d][h] = v.Float64()
+				}
+			}
+			format = func(v float64) string { return strconv.FormatFloat(v, 'f', 2, 64) }
+		default:
+			fmt.Println("invalid metric: ", *metric)
+			return
+		}
+
+		err := out.writeTo(func(w io.Writer) error { return heatmap.WriteSVG(w, title, grid, format) })
+		if err != nil {
+			fmt.Println("error writing heatmap: ", err)
+		}
+		return
+	}
+
+	columns := []report.Column{{Name: "weekday"}}
+	for h := 0; h < 24; h++ {
+		columns = append(columns, report.Column{Name: fmt.Sprintf("h%02d", h)})
+	}
+
+	payments := report.NewTable(fmt.Sprintf("%s payments from %s to %s", *location, from, to), columns...)
+	revenue := report.NewTable(fmt.Sprintf("%s revenue from %s to %s", *location, from, to), columns...)
+	for d, day := range heatmap.Weekdays {
+		paymentsRow := []any{day}
+		revenueRow := []any{day}
+		for h := 0; h < 24; h++ {
+			paymentsRow = append(paymentsRow, hm.Payments[d][h])
+			revenueRow = append(revenueRow, hm.Revenue[d][h])
+		}
+		payments.Append(paymentsRow...)
+		revenue.Append(revenueRow...)
+	}
+
+	if err := out.write(conf.Privacy, payments, revenue); err != nil {
+		fmt.Println("error writing heatmap: ", err)
+	}
+}
+
+func reportAnomalies(ctx context.Context, conf config.Config, anomalyService *service.AnomalyService, args []string) {
+	flags := flag.NewFlagSet("report anomalies", flag.ContinueOnError)
+	dates := addDateRangeFlags(flags)
+	minPayments := flags.Int("min-payments", conf.Anomaly.MinPayments, "payments needed before a refund ratio counts")
+	refundRatio := flags.Float64("refund-ratio", conf.Anomaly.RefundRatio, "refunded share of the paid amount to flag")
+	maxDiscounts := flags.Int("max-discounts", conf.Anomaly.MaxDiscountRepeats, "payments with the largest discount of a location to flag")
+	burstCount := flags.Int("burst-count", conf.Anomaly.BurstCount, "transactions within the burst window to flag")
+	burstWindow := flags.Duration("burst-window", conf.Anomaly.BurstWindow, "burst window")
+	out := addOutputFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return
+	}
+
+	from, to, err := dates.dates()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	anomalies, err := anomalyService.Anomalies(ctx, from, to, domain.AnomalyThresholds{
+		MinPayments:        *minPayments,
+		RefundRatio:        *refundRatio,
+		MaxDiscountRepeats: *maxDiscounts,
+		BurstCount:         *burstCount,
+		BurstWindow:        *burstWindow,
+	})
+	if err != nil {
+		fmt.Println("error detecting anomalies: ", err)
+		return
+	}
+
+	customers := report.NewTable(fmt.Sprintf("customer anomalies from %s to %s", from, to),
+		report.Column{Name: "user_id", Kind: report.UserID},
+		report.Column{Name: "kind"},
+		report.Column{Name: "value"},
+		report.Column{Name: "threshold"},
+		report.Column{Name: "transactions"},
+		report.Column{Name: "first_at"},
+		report.Column{Name: "last_at"},
+		report.Column{Name: "detail"},
+	)
+	staff := report.NewTable(fmt.Sprintf("staff anomalies from %s to %s", from, to),
+		report.Column{Name: "staff_id"},
+		report.Column{Name: "kind"},
+		report.Column{Name: "value"},
+		report.Column{Name: "threshold"},
+		report.Column{Name: "transactions"},
+		report.Column{Name: "first_at"},
+		report.Column{Name: "last_at"},
+		report.Column{Name: "detail"},
+	)
+	for _, a := range anomalies {
+		table := customers
+		if a.Subject == domain.AnomalySubjectStaff {
+			table = staff
+		}
+		table.Append(a.SubjectID, a.Kind, a.Value, a.Threshold, a.Payments, a.FirstAt, a.LastAt, a.Detail)
+	}
+
+	if err := out.write(conf.Privacy, customers, staff); err != nil {
+		fmt.Println("error writing anomalies: ", err)
+	}
+}
+
+func reportStaff(ctx context.Context, conf config.Config, reportService *service.ReportService, args []string) {
+	flags := flag.NewFlagSet("report staff", flag.ContinueOnError)
+	location := flags.String("location", "", "location ID or a part of its title")
+	groupBy := flags.String("group-by", domain.GroupByMonth, "period: day, week or month")
+	dates := addDateRangeFlags(flags)
+	out := addOutputFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return
+	}
+	if *location == "" {
+		fmt.Println("--location is required")
+		return
+	}
+
+	from, to, err := dates.dates()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	activity, err := reportService.StaffActivity(ctx, *location, from, to, *groupBy)
+	if err != nil {
+		fmt.Println("error building staff report: ", err)
+		return
+	}
+
+	table := report.NewTable(fmt.Sprintf("%s staff from %s to %s by %s", *location, from, to, *groupBy),
+		report.Column{Name: "period"},
+		report.Column{Name: "staff_id"},
+		report.Column{Name: "name", Kind: report.Name},
+		report.Column{Name: "payments"},
+		report.Column{Name: "revenue"},
+		report.Column{Name: "discounts"},
+		report.Column{Name: "refunds"},
+		report.Column{Name: "refund_amount"},
+	)
+	for _, a := range activity {
+		table.Append(a.Period, a.StaffID, a.StaffName, a.Payments, a.Revenue, a.Discounts, a.Refunds, a.RefundAmount)
+	}
+
+	if err := out.write(conf.Privacy, table); err != nil {
+		fmt.Println("error writing staff report: ", err)
+	}
+}
+
+func reportForecast(ctx context.Context, conf config.Config, forecastService *service.ForecastService, args []string) {
+	flags := flag.NewFlagSet("report forecast", flag.ContinueOnError)
+	location := flags.String("location", "", "a part of the location title")
+	days := flags.Int("days", 14, "days to forecast from today on")
+	historyDays := flags.Int("history-days", 182, "days of revenue history the model is fitted to")
+	out := addOutputFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return
+	}
+	if *location == "" {
+		fmt.Println("--location is required")
+		return
+	}
+
+	forecasts, err := forecastService.Forecast(ctx, *location, *days, *historyDays, time.Now())
+	if err != nil {
+		fmt.Println("error building forecast: ", err)
+		return
+	}
+
+	title := fmt.Sprintf("%s net revenue forecast, 95%% intervals", *location)
+	if len(forecasts) > 0 {
+		title += ", " + forecasts[0].Model
+	}
+
+	table := report.NewTable(title,
+		report.Column{Name: "day"},
+		report.Column{Name: "weekday"},
+		report.Column{Name: "predicted"},
+		report.Column{Name: "lower"},
+		report.Column{Name: "upper"},
+	)
+	for _, f := range forecasts {
+		table.Append(f.Day, f.Day.In(time.UTC).Weekday().String(), f.Predicted, f.Lower, f.Upper)
+	}
+
+	if err := out.write(conf.Privacy, table); err != nil {
+		fmt.Println("error writing forecast: ", err)
+	}
+}
+
+func reportLifecycle(ctx context.Context, conf config.Config, lifecycleService *service.LifecycleService, args []string) {
+	flags := flag.NewFlagSet("report lifecycle", flag.ContinueOnError)
+	dates := addDateRangeFlags(flags)
+	out := addOutputFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return
+	}
+
+	from, to, err := dates.dates()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	counts, err := lifecycleService.TransitionCounts(ctx, from, to)
+	if err != nil {
+		fmt.Println("error building lifecycle report: ", err)
+		return
+	}
+
+	table := report.NewTable(fmt.Sprintf("lifecycle transitions from %s to %s", from, to),
+		report.Column{Name: "day"},
+		report.Column{Name: "from"},
+		report.Column{Name: "to"},
+		report.Column{Name: "customers"},
+	)
+	for _, c := range counts {
+		table.Append(c.Day, c.From, c.To, c.Customers)
+	}
+
+	if err := out.write(conf.Privacy, table); err != nil {
+		fmt.Println("error writing lifecycle report: ", err)
+	}
+}
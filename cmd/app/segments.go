@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/ibookerke/choco_parser_go/internal/config"
+	"github.com/ibookerke/choco_parser_go/internal/domain"
+	"github.com/ibookerke/choco_parser_go/internal/pkg/report"
+	"github.com/ibookerke/choco_parser_go/internal/repository"
+	"github.com/ibookerke/choco_parser_go/internal/service"
+)
+
+func segmentsRFM(ctx context.Context, conf config.Config, segmentService *service.SegmentService, args []string) {
+	flags := flag.NewFlagSet("segments rfm", flag.ContinueOnError)
+	company := flags.String("company", "", "company name the terminals are matched by")
+	out := addOutputFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return
+	}
+	if *company == "" {
+		fmt.Println("--company is required")
+		return
+	}
+
+	run, err := segmentService.RunRFM(ctx, *company, time.Now())
+	if err != nil {
+		fmt.Println("error running rfm segmentation: ", err)
+		return
+	}
+
+	table := report.NewTable(
+		fmt.Sprintf("RFM segments of %s on %s from %s, %d customers", run.Company, run.RunDate, run.Source, len(run.Segments)),
+		report.Column{Name: "segment"},
+		report.Column{Name: "customers"},
+		report.Column{Name: "customers_pct"},
+		report.Column{Name: "revenue"},
+		report.Column{Name: "revenue_pct"},
+	)
+	for _, s := range run.Summary {
+		table.Append(s.Segment, s.Customers, s.CustomerShare, s.Revenue, s.RevenueShare)
+	}
+
+	if err := out.write(conf.Privacy, table); err != nil {
+		fmt.Println("error writing rfm segments: ", err)
+	}
+}
+
+func segmentsSave(ctx context.Context, conf config.Config, savedSegmentService *service.SavedSegmentService, args []string) {
+	if len(args) == 0 {
+		fmt.Println(`usage: segments save <name> --filter 'turnover > 50000 AND last_visit < 30d AND company = "malatang"'`)
+		return
+	}
+
+	flags := flag.NewFlagSet("segments save", flag.ContinueOnError)
+	filter := flags.String("filter", "", "segment filter, fields: company, name, turnover, visits, average_bill, orders, last_visit")
+	out := addOutputFlags(flags)
+	if err := flags.Parse(args[1:]); err != nil {
+		return
+	}
+	if *filter == "" {
+		fmt.Println("--filter is required")
+		return
+	}
+
+	segment, err := savedSegmentService.Save(ctx, args[0], *filter, time.Now())
+	if err != nil {
+		fmt.Println("error saving segment: ", err)
+		return
+	}
+
+	if err := out.write(conf.Privacy, savedSegmentsTable([]domain.SavedSegment{*segment})); err != nil {
+		fmt.Println("error writing segment: ", err)
+	}
+}
+
+func segmentsEval(ctx context.Context, conf config.Config, savedSegmentService *service.SavedSegmentService, args []string) {
+	if len(args) == 0 {
+		fmt.Println("usage: segments eval <name>")
+		return
+	}
+
+	flags := flag.NewFlagSet("segments eval", flag.ContinueOnError)
+	out := addOutputFlags(flags)
+	if err := flags.Parse(args[1:]); err != nil {
+		return
+	}
+
+	eval, err := savedSegmentService.Eval(ctx, args[0], time.Now())
+	if errors.Is(err, repository.ErrNotFound) {
+		fmt.Println("segment not found: ", args[0])
+		return
+	}
+	if err != nil {
+		fmt.Println("error evaluating segment: ", err)
+		return
+	}
+
+	table := report.NewTable("",
+		report.Column{Name: "name"},
+		report.Column{Name: "filter"},
+		report.Column{Name: "members"},
+		report.Column{Name: "entered"},
+		report.Column{Name: "left"},
+		report.Column{Name: "evaluated_at"},
+	)
+	table.Append(eval.Segment.Name, eval.Segment.Filter, eval.Segment.Members, len(eval.Entered), len(eval.Left), eval.Segment.EvaluatedAt)
+
+	if err := out.write(conf.Privacy, table); err != nil {
+		fmt.Println("error writing segment: ", err)
+	}
+}
+
+func segmentsChanges(ctx context.Context, conf config.Config, savedSegmentService *service.SavedSegmentService, args []string) {
+	if len(args) == 0 {
+		fmt.Println("usage: segments changes <name> [--event entered|left] [--from YYYY-MM-DD] [--to YYYY-MM-DD]")
+		return
+	}
+
+	flags := flag.NewFlagSet("segments changes", flag.ContinueOnError)
+	event := flags.String("event", "", "entered or left, both when empty")
+	dates := addDateRangeFlags(flags)
+	out := addOutputFlags(flags)
+	if err := flags.Parse(args[1:]); err != nil {
+		return
+	}
+
+	from, to, err := dates.dates()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	changes, err := savedSegmentService.Changes(ctx, args[0], *event, from, to)
+	if errors.Is(err, repository.ErrNotFound) {
+		fmt.Println("segment not found: ", args[0])
+		return
+	}
+	if err != nil {
+		fmt.Println("error getting segment changes: ", err)
+		return
+	}
+
+	table := report.NewTable("",
+		report.Column{Name: "at"},
+		report.Column{Name: "event"},
+		report.Column{Name: "user_id", Kind: report.UserID},
+		report.Column{Name: "full_name", Kind: report.Name},
+		report.Column{Name: "phone", Kind: report.Phone},
+	)
+	for _, c := range changes {
+		table.Append(c.At, c.Event, c.UserID, c.FullName, c.Phone)
+	}
+
+	if err := out.write(conf.Privacy, table); err != nil {
+		fmt.Println("error writing segment changes: ", err)
+	}
+}
+
+func segmentsList(ctx context.Context, conf config.Config, savedSegmentService *service.SavedSegmentService, args []string) {
+	flags := flag.NewFlagSet("segments list", flag.ContinueOnError)
+	out := addOutputFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return
+	}
+
+	segments, err := savedSegmentService.List(ctx)
+	if err != nil {
+		fmt.Println("error listing segments: ", err)
+		return
+	}
+
+	if err := out.write(conf.Privacy, savedSegmentsTable(segments)); err != nil {
+		fmt.Println("error writing segments: ", err)
+	}
+}
+
+func savedSegmentsTable(segments []domain.SavedSegment) *report.Table {
+	table := report.NewTable("",
+		report.Column{Name: "name"},
+		report.Column{Name: "filter"},
+		report.Column{Name: "members"},
+		report.Column{Name: "evaluated_at"},
+		report.Column{Name: "updated_at"},
+	)
+	for _, s := range segments {
+		table.Append(s.Name, s.Filter, s.Members, s.EvaluatedAt, s.UpdatedAt)
+	}
+
+	return table
+}
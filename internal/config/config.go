@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
 )
@@ -25,6 +26,62 @@ type Choco struct {
 	ChocoToken  string `env:"CHOCO_AUTH_TOKEN"`
 }
 
+// Privacy is a configuration for masked exports.
+type Privacy struct {
+	PseudonymKey string `env:"PRIVACY_PSEUDONYM_KEY" env-description:"HMAC key for user ID pseudonyms in masked exports, at least 16 bytes"`
+}
+
+// Anomaly holds the default thresholds of the anomalies report.
+type Anomaly struct {
+	MinPayments        int           `env:"ANOMALY_MIN_PAYMENTS" env-default:"5" env-description:"payments needed before a refund ratio counts"`
+	RefundRatio        float64       `env:"ANOMALY_REFUND_RATIO" env-default:"0.3" env-description:"refunded share of the paid amount to flag"`
+	MaxDiscountRepeats int           `env:"ANOMALY_MAX_DISCOUNT_REPEATS" env-default:"3" env-description:"payments with the largest discount of a location to flag"`
+	BurstCount         int           `env:"ANOMALY_BURST_COUNT" env-default:"4" env-description:"transactions within the burst window to flag"`
+	BurstWindow        time.Duration `env:"ANOMALY_BURST_WINDOW" env-default:"10m" env-description:"burst window"`
+}
+
+// Alerts is a configuration for the alert rules checked after every sync.
+// The rules are read from a YAML or JSON file, see AlertRule.
+type Alerts struct {
+	RulesFile string `env:"ALERT_RULES_FILE" env-description:"YAML or JSON file with the alert rules"`
+	Rules     []AlertRule
+}
+
+// AlertRule is a rule as written in the rules file, e.g.
+//
+//	rules:
+//	  - name: dostyk_revenue_drop
+//	    kind: revenue_drop
+//	    location: Dostyk
+//	    drop: 0.3
+//	    weeks: 4
+//	  - name: quiet_hours
+//	    kind: no_payments
+//	    window: 3h
+//	    opens: "10:00"
+//	    closes: "22:00"
+type AlertRule struct {
+	Name     string  `yaml:"name" json:"name"`
+	Kind     string  `yaml:"kind" json:"kind"`
+	Location string  `yaml:"location" json:"location"`
+	Drop     float64 `yaml:"drop" json:"drop"`
+	Weeks    int     `yaml:"weeks" json:"weeks"`
+	Window   string  `yaml:"window" json:"window"`
+	Opens    string  `yaml:"opens" json:"opens"`
+	Closes   string  `yaml:"closes" json:"closes"`
+}
+
+// Notify is a configuration for the notification backends, messages are printed to stdout when none is set.
+type Notify struct {
+	WebhookURL    string   `env:"NOTIFY_WEBHOOK_URL" env-description:"URL the JSON messages are posted to"`
+	WebhookSecret string   `env:"NOTIFY_WEBHOOK_SECRET" env-description:"HMAC-SHA256 key of the X-Signature header"`
+	SMTPAddr      string   `env:"NOTIFY_SMTP_ADDR" env-description:"SMTP server host:port"`
+	SMTPUsername  string   `env:"NOTIFY_SMTP_USERNAME"`
+	SMTPPassword  string   `env:"NOTIFY_SMTP_PASSWORD"`
+	SMTPFrom      string   `env:"NOTIFY_SMTP_FROM"`
+	SMTPTo        []string `env:"NOTIFY_SMTP_TO" env-separator:"," env-description:"comma separated recipients"`
+}
+
 // Logger is a configuration for logger.
 type Logger struct {
 	LogLevel string `env:"LOG_LEVEL" env-default:"info"`
@@ -38,6 +95,10 @@ type Config struct {
 	Database Database
 	Logger   Logger
 	Choco    Choco
+	Privacy  Privacy
+	Anomaly  Anomaly
+	Alerts   Alerts
+	Notify   Notify
 }
 
 func Get() (Config, error) {
@@ -59,5 +120,31 @@ func Get() (Config, error) {
 		return config, fmt.Errorf("error reading choco config: %w", err)
 	}
 
+	if err := cleanenv.ReadEnv(&config.Privacy); err != nil {
+		return config, fmt.Errorf("error reading privacy config: %w", err)
+	}
+
+	if err := cleanenv.ReadEnv(&config.Anomaly); err != nil {
+		return config, fmt.Errorf("error reading anomaly config: %w", err)
+	}
+
+	if err := cleanenv.ReadEnv(&config.Alerts); err != nil {
+		return config, fmt.Errorf("error reading alerts config: %w", err)
+	}
+
+	if err := cleanenv.ReadEnv(&config.Notify); err != nil {
+		return config, fmt.Errorf("error reading notify config: %w", err)
+	}
+
+	if config.Alerts.RulesFile != "" {
+		var rules struct {
+			Rules []AlertRule `yaml:"rules" json:"rules"`
+		}
+		if err := cleanenv.ReadConfig(config.Alerts.RulesFile, &rules); err != nil {
+			return config, fmt.Errorf("error reading alert rules: %w", err)
+		}
+		config.Alerts.Rules = rules.Rules
+	}
+
 	return config, nil
 }
@@ -0,0 +1,204 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Alert rule kinds.
+const (
+	// AlertRevenueDrop fires when a location's net revenue of a day falls more than Drop
+	// below its average on the same weekday of the previous Weeks weeks.
+	AlertRevenueDrop = "revenue_drop"
+	// AlertNoPayments fires when a location has no payments for Window within its opening time.
+	AlertNoPayments = "no_payments"
+)
+
+// AlertRule is a validated rule. An empty Location matches every location, otherwise
+// it matches the locations whose title contains it. Opens and Closes are offsets from midnight.
+type AlertRule struct {
+	Name     string
+	Kind     string
+	Location string
+	Drop     float64
+	Weeks    int
+	Window   time.Duration
+	Opens    time.Duration
+	Closes   time.Duration
+}
+
+// Alert is a fired rule. Key tells occurrences of a rule at a location apart,
+// the same occurrence is stored once and sent until a send succeeds.
+type Alert struct {
+	ID          int64     `json:"id"`
+	Rule        string    `json:"rule"`
+	Kind        string    `json:"kind"`
+	Location    string    `json:"location"`
+	Key         string    `json:"key"`
+	Message     string    `json:"message"`
+	TriggeredAt time.Time `json:"triggered_at"`
+}
+
+// LocationRevenue is the net revenue of a location on a day.
+type LocationRevenue struct {
+	Day      Date
+	Location string
+	Net      Money
+}
+
+// LocationPayments are the times of the payments at a location in ascending order.
+type LocationPayments struct {
+	Location string
+	Times    []time.Time
+}
+
+type AlertRepository interface {
+	// PaymentTimes lists the payments of the locations matching location since the given time,
+	// each location starts with its latest payment before it when there is one.
+	PaymentTimes(ctx context.Context, location string, since time.Time) ([]LocationPayments, error)
+	// EvaluatedAt is the time the rule was last evaluated at, zero when it never was.
+	EvaluatedAt(ctx context.Context, rule string) (time.Time, error)
+	SetEvaluatedAt(ctx context.Context, rule string, at time.Time) error
+	// Store saves the alert unless the same rule, location and key were stored before,
+	// it reports whether the alert is new.
+	Store(ctx context.Context, alert *Alert) (bool, error)
+	// Unnotified lists the stored alerts of the rule that were not sent yet.
+	Unnotified(ctx context.Context, rule string) ([]Alert, error)
+	MarkNotified(ctx context.Context, id int64, at time.Time) error
+}
+
+// RevenueDropAlerts checks the day against the same weekday of the previous weeks in revenue.
+// Locations missing on the day had no revenue then, weekdays missing before are left out of the average.
+func RevenueDropAlerts(rule AlertRule, day Date, revenue []LocationRevenue, now time.Time) []Alert {
+	type history struct {
+		today    Money
+		previous []Money
+	}
+
+	byLocation := make(map[string]*history)
+	for _, r := range revenue {
+		h := byLocation[r.Location]
+		if h == nil {
+			h = &history{}
+			byLocation[r.Location] = h
+		}
+
+		days := r.Day.DaysUntil(day)
+		switch {
+		case days == 0:
+			h.today += r.Net
+		case days > 0 && days%7 == 0 && days/7 <= rule.Weeks:
+			h.previous = append(h.previous, r.Net)
+		}
+	}
+
+	var alerts []Alert
+	for location, h := range byLocation {
+		if len(h.previous) == 0 {
+			continue
+		}
+
+		var sum Money
+		for _, net := range h.previous {
+			sum += net
+		}
+		average := sum.MulRat(1, int64(len(h.previous)))
+		if average <= 0 {
+			continue
+		}
+
+		drop := 1 - float64(h.today)/float64(average)
+		if drop <= rule.Drop {
+			continue
+		}
+
+		alerts = append(alerts, Alert{
+			Rule:     rule.Name,
+			Kind:     rule.Kind,
+			Location: location,
+			Key:      day.String(),
+			Message: fmt.Sprintf("net revenue at %s on %s %s is %s, %.0f%% below the average of %s over %d previous %ss",
+				location, day.In(time.UTC).Weekday(), day, h.today, drop*100, average, len(h.previous), day.In(time.UTC).Weekday()),
+			TriggeredAt: now,
+		})
+	}
+
+	sortAlerts(alerts)
+
+	return alerts
+}
+
+// NoPaymentsAlerts scans the gaps between consecutive payments of every location from since to now,
+// the last gap runs until now and the gaps ending before since were scanned before. A gap is measured
+// within the opening time of each day it spans, days are taken in the location of now.
+func NoPaymentsAlerts(rule AlertRule, since, now time.Time, payments []LocationPayments) []Alert {
+	var alerts []Alert
+	for _, l := range payments {
+		start := since
+		if len(l.Times) > 0 && l.Times[0].Before(since) {
+			start = l.Times[0]
+		}
+
+		for i, t := range l.Times {
+			if t.After(start) && t.After(since) {
+				alerts = append(alerts, quietAlerts(rule, l.Location, start, t, since, now, i > 0)...)
+			}
+			start = t
+		}
+		if now.After(start) {
+			alerts = append(alerts, quietAlerts(rule, l.Location, start, now, since, now, len(l.Times) > 0)...)
+		}
+	}
+
+	sortAlerts(alerts)
+
+	return alerts
+}
+
+// quietAlerts checks the gap from the payment at start, or from since when paid is false, until end.
+func quietAlerts(rule AlertRule, location string, start, end, since, now time.Time, paid bool) []Alert {
+	lastPayment, key := "none yet", int64(0)
+	if paid {
+		lastPayment, key = start.In(now.Location()).Format("2006-01-02 15:04"), start.Unix()
+	}
+
+	var alerts []Alert
+	for midnight := DateOf(start.In(now.Location())).In(now.Location()); midnight.Before(end); midnight = midnight.AddDate(0, 0, 1) {
+		opens, closes := midnight.Add(rule.Opens), midnight.Add(rule.Closes)
+		if !closes.After(since) {
+			continue
+		}
+
+		from, to := opens, closes
+		if start.After(from) {
+			from = start
+		}
+		if end.Before(to) {
+			to = end
+		}
+
+		quiet := to.Sub(from)
+		if quiet < rule.Window {
+			continue
+		}
+
+		day := DateOf(midnight)
+		alerts = append(alerts, Alert{
+			Rule:     rule.Name,
+			Kind:     rule.Kind,
+			Location: location,
+			Key:      fmt.Sprintf("%s/%d", day, key),
+			Message: fmt.Sprintf("no payments at %s for %s during opening time on %s, the last one was at %s",
+				location, quiet.Truncate(time.Minute), day, lastPayment),
+			TriggeredAt: now,
+		})
+	}
+
+	return alerts
+}
+
+func sortAlerts(alerts []Alert) {
+	sort.Slice(alerts, func(i, j int) bool { return alerts[i].Location < alerts[j].Location })
+}
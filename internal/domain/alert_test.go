@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRevenueDropAlerts(t *testing.T) {
+	t.Parallel()
+
+	day := NewDate(2025, time.May, 14)
+	rule := AlertRule{Name: "drop", Kind: AlertRevenueDrop, Drop: 0.3, Weeks: 2}
+	revenue := []LocationRevenue{
+		{Day: NewDate(2025, time.April, 30), Location: "Dostyk", Net: 100000},
+		{Day: NewDate(2025, time.May, 7), Location: "Dostyk", Net: 140000},
+		{Day: NewDate(2025, time.May, 13), Location: "Dostyk", Net: 900000},
+		{Day: day, Location: "Dostyk", Net: 80000},
+		// three weeks back is outside the rule
+		{Day: NewDate(2025, time.April, 23), Location: "Abaya", Net: 500000},
+		{Day: NewDate(2025, time.May, 7), Location: "Abaya", Net: 100000},
+		{Day: day, Location: "Abaya", Net: 75000},
+		// closed on the day
+		{Day: NewDate(2025, time.May, 7), Location: "Sairan", Net: 50000},
+	}
+
+	alerts := RevenueDropAlerts(rule, day, revenue, time.Now())
+	require.Len(t, alerts, 2)
+
+	assert.Equal(t, "Dostyk", alerts[0].Location)
+	assert.Equal(t, "2025-05-14", alerts[0].Key)
+	assert.Contains(t, alerts[0].Message, "33% below the average of 1200.00 over 2 previous Wednesdays")
+
+	assert.Equal(t, "Sairan", alerts[1].Location)
+	assert.Contains(t, alerts[1].Message, "100% below")
+}
+
+func TestNoPaymentsAlerts(t *testing.T) {
+	t.Parallel()
+
+	rule := AlertRule{Name: "quiet", Kind: AlertNoPayments, Window: 3 * time.Hour, Opens: 10 * time.Hour, Closes: 22 * time.Hour}
+	day := func(hour, minute int) time.Time { return time.Date(2025, time.May, 14, hour, minute, 0, 0, time.UTC) }
+	payments := []LocationPayments{
+		{Location: "Abaya", Times: []time.Time{day(9, 0), day(12, 30), day(14, 30)}},
+		{Location: "Dostyk", Times: []time.Time{day(11, 0)}},
+		{Location: "Medeu", Times: []time.Time{day(10, 30), day(14, 0), day(14, 30)}},
+		{Location: "Sairan", Times: []time.Time{day(0, 0).AddDate(0, 0, -1)}},
+	}
+
+	alerts := NoPaymentsAlerts(rule, day(12, 0), day(15, 0), payments)
+	require.Len(t, alerts, 3)
+	assert.Equal(t, "Dostyk", alerts[0].Location)
+	assert.Contains(t, alerts[0].Message, "for 4h0m0s")
+	assert.Equal(t, "Medeu", alerts[1].Location, "a gap between two payments")
+	assert.Contains(t, alerts[1].Message, "for 3h30m0s")
+	assert.Equal(t, "Sairan", alerts[2].Location, "the gap counts from opening, the previous day ended before since")
+	assert.Contains(t, alerts[2].Message, "for 5h0m0s")
+
+	closed := NoPaymentsAlerts(rule, day(15, 0), day(23, 0), payments)
+	require.Len(t, closed, 4, "evaluated after closing")
+	assert.Equal(t, "Abaya", closed[0].Location)
+	assert.Contains(t, closed[0].Message, "for 7h30m0s")
+	assert.Equal(t, alerts[0].Key, closed[1].Key, "the same gap keeps its key")
+
+	assert.Empty(t, NoPaymentsAlerts(rule, day(9, 0), day(12, 0), payments[:2]))
+}
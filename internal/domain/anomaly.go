@@ -0,0 +1,246 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Anomaly subjects.
+const (
+	AnomalySubjectCustomer = "customer"
+	AnomalySubjectStaff    = "staff"
+)
+
+// Anomaly kinds.
+const (
+	AnomalyRefundRatio = "refund_ratio"
+	AnomalyMaxDiscount = "max_discount"
+	AnomalyBurst       = "burst"
+)
+
+// AnomalyThresholds tell when behaviour is unusual enough to flag.
+// MinPayments is the number of payments a subject needs before its refund ratio counts.
+type AnomalyThresholds struct {
+	MinPayments        int
+	RefundRatio        float64
+	MaxDiscountRepeats int
+	BurstCount         int
+	BurstWindow        time.Duration
+}
+
+// Anomaly is a customer or staff member whose payments look unusual.
+// Value is the figure compared with the threshold of the kind.
+type Anomaly struct {
+	Subject   string
+	SubjectID int64
+	Kind      string
+	Value     float64
+	Threshold float64
+	Payments  int
+	FirstAt   time.Time
+	LastAt    time.Time
+	Detail    string
+}
+
+// DetectAnomalies flags customers (Payment.UserID) and staff (Payment.CreatedBy) with
+//   - refunds of at least RefundRatio of their paid amount,
+//   - at least MaxDiscountRepeats payments with the largest discount share given at the location,
+//   - at least BurstCount transactions within BurstWindow: payments for customers, refunds for staff,
+//     as staff make many payments in a row by design.
+//
+// Results are ordered by kind and then by value, highest first.
+func DetectAnomalies(payments []Payment, t AnomalyThresholds) []Anomaly {
+	maxShares := maxDiscountShares(payments)
+
+	var anomalies []Anomaly
+	for _, subject := range []string{AnomalySubjectCustomer, AnomalySubjectStaff} {
+		for id, ps := range groupBySubject(payments, subject) {
+			anomalies = appendIf(anomalies, refundRatio(subject, id, ps, t))
+			anomalies = appendIf(anomalies, maxDiscounts(subject, id, ps, maxShares, t))
+
+			burstType := PaymentTypePay
+			if subject == AnomalySubjectStaff {
+				burstType = PaymentTypeRefund
+			}
+			anomalies = appendIf(anomalies, burst(subject, id, ps, burstType, t))
+		}
+	}
+
+	sort.Slice(anomalies, func(i, j int) bool {
+		a, b := anomalies[i], anomalies[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Value != b.Value {
+			return a.Value > b.Value
+		}
+		if a.Subject != b.Subject {
+			return a.Subject < b.Subject
+		}
+		return a.SubjectID < b.SubjectID
+	})
+
+	return anomalies
+}
+
+func appendIf(anomalies []Anomaly, a *Anomaly) []Anomaly {
+	if a == nil {
+		return anomalies
+	}
+
+	return append(anomalies, *a)
+}
+
+func groupBySubject(payments []Payment, subject string) map[int64][]Payment {
+	groups := make(map[int64][]Payment)
+	for _, p := range payments {
+		id := p.UserID
+		if subject == AnomalySubjectStaff {
+			id = p.CreatedBy
+		}
+		if id == 0 {
+			continue
+		}
+		groups[id] = append(groups[id], p)
+	}
+
+	for _, ps := range groups {
+		sort.SliceStable(ps, func(i, j int) bool { return ps[i].CreatedAt.Before(ps[j].CreatedAt) })
+	}
+
+	return groups
+}
+
+func refundRatio(subject string, id int64, ps []Payment, t AnomalyThresholds) *Anomaly {
+	var (
+		paid, refunded Money
+		pays, refunds  int
+	)
+	for _, p := range ps {
+		switch p.Type {
+		case PaymentTypePay:
+			paid += p.Amount
+			pays++
+		case PaymentTypeRefund:
+			refunded += p.RefundAmount()
+			refunds++
+		}
+	}
+
+	if pays < t.MinPayments || paid <= 0 || refunds == 0 {
+		return nil
+	}
+
+	ratio := float64(refunded) / float64(paid)
+	if ratio < t.RefundRatio {
+		return nil
+	}
+
+	return &Anomaly{
+		Subject:   subject,
+		SubjectID: id,
+		Kind:      AnomalyRefundRatio,
+		Value:     ratio,
+		Threshold: t.RefundRatio,
+		Payments:  pays + refunds,
+		FirstAt:   ps[0].CreatedAt,
+		LastAt:    ps[len(ps)-1].CreatedAt,
+		Detail:    fmt.Sprintf("%d refunds of %s for %d payments of %s", refunds, refunded, pays, paid),
+	}
+}
+
+// discountShare is the part of the pre-discount price a payment got as a discount.
+func discountShare(p Payment) float64 {
+	full := p.Amount + p.DiscountAmount
+	if p.Type != PaymentTypePay || p.DiscountAmount <= 0 || full <= 0 {
+		return 0
+	}
+
+	return float64(p.DiscountAmount) / float64(full)
+}
+
+func locationKey(p Payment) string {
+	if p.LocationID != "" {
+		return p.LocationID
+	}
+
+	return strings.ToLower(strings.TrimSpace(p.LocationTitle))
+}
+
+func maxDiscountShares(payments []Payment) map[string]float64 {
+	shares := make(map[string]float64)
+	for _, p := range payments {
+		key := locationKey(p)
+		shares[key] = max(shares[key], discountShare(p))
+	}
+
+	return shares
+}
+
+// shareEpsilon absorbs the rounding of discounts to tiyn when comparing shares.
+const shareEpsilon = 0.0005
+
+func maxDiscounts(subject string, id int64, ps []Payment, maxShares map[string]float64, t AnomalyThresholds) *Anomaly {
+	var hits []Payment
+	for _, p := range ps {
+		share := discountShare(p)
+		if share > 0 && share >= maxShares[locationKey(p)]-shareEpsilon {
+			hits = append(hits, p)
+		}
+	}
+
+	if len(hits) == 0 || len(hits) < t.MaxDiscountRepeats {
+		return nil
+	}
+
+	return &Anomaly{
+		Subject:   subject,
+		SubjectID: id,
+		Kind:      AnomalyMaxDiscount,
+		Value:     float64(len(hits)),
+		Threshold: float64(t.MaxDiscountRepeats),
+		Payments:  len(hits),
+		FirstAt:   hits[0].CreatedAt,
+		LastAt:    hits[len(hits)-1].CreatedAt,
+		Detail:    fmt.Sprintf("%d payments with the largest discount of the location", len(hits)),
+	}
+}
+
+// burst finds the densest run of transactions of the type within the window.
+func burst(subject string, id int64, ps []Payment, typ PaymentType, t AnomalyThresholds) *Anomaly {
+	var times []time.Time
+	for _, p := range ps {
+		if p.Type == typ {
+			times = append(times, p.CreatedAt)
+		}
+	}
+
+	best, bestStart := 0, 0
+	start := 0
+	for end := range times {
+		for times[end].Sub(times[start]) > t.BurstWindow {
+			start++
+		}
+		if n := end - start + 1; n > best {
+			best, bestStart = n, start
+		}
+	}
+
+	if best == 0 || best < t.BurstCount {
+		return nil
+	}
+
+	return &Anomaly{
+		Subject:   subject,
+		SubjectID: id,
+		Kind:      AnomalyBurst,
+		Value:     float64(best),
+		Threshold: float64(t.BurstCount),
+		Payments:  best,
+		FirstAt:   times[bestStart],
+		LastAt:    times[bestStart+best-1],
+		Detail:    fmt.Sprintf("%d %s transactions within %s", best, typ, t.BurstWindow),
+	}
+}
@@ -0,0 +1,91 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectAnomalies(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2025, time.April, 1, 12, 0, 0, 0, time.UTC)
+	payment := func(typ PaymentType, user, staff int64, amount, discount Money, minutes int) Payment {
+		return Payment{
+			Type:           typ,
+			UserID:         user,
+			CreatedBy:      staff,
+			Amount:         amount,
+			DiscountAmount: discount,
+			CreatedAt:      start.Add(time.Duration(minutes) * time.Minute),
+			LocationTitle:  "Dostyk",
+		}
+	}
+
+	payments := []Payment{
+		// customer 1 gets the top 20% discount from staff 9 three times, once a day
+		payment(PaymentTypePay, 1, 9, 8000, 2000, 0),
+		payment(PaymentTypePay, 1, 9, 8000, 2000, 24*60),
+		payment(PaymentTypePay, 1, 9, 8000, 2000, 48*60),
+		// customer 2 pays four times within ten minutes and refunds most of it
+		payment(PaymentTypePay, 2, 5, 10000, 0, 100),
+		payment(PaymentTypePay, 2, 5, 10000, 1000, 103),
+		payment(PaymentTypePay, 2, 5, 10000, 0, 106),
+		payment(PaymentTypePay, 2, 5, 10000, 0, 109),
+		payment(PaymentTypeRefund, 2, 5, -30000, 0, 200),
+	}
+
+	got := DetectAnomalies(payments, AnomalyThresholds{
+		MinPayments:        3,
+		RefundRatio:        0.5,
+		MaxDiscountRepeats: 3,
+		BurstCount:         4,
+		BurstWindow:        10 * time.Minute,
+	})
+
+	type key struct {
+		subject string
+		id      int64
+		kind    string
+	}
+	found := make(map[key]Anomaly)
+	for _, a := range got {
+		found[key{a.Subject, a.SubjectID, a.Kind}] = a
+	}
+	require.Len(t, found, 5)
+
+	assert.Equal(t, 3.0, found[key{AnomalySubjectCustomer, 1, AnomalyMaxDiscount}].Value)
+	assert.Equal(t, 3.0, found[key{AnomalySubjectStaff, 9, AnomalyMaxDiscount}].Value)
+
+	burst := found[key{AnomalySubjectCustomer, 2, AnomalyBurst}]
+	assert.Equal(t, 4.0, burst.Value)
+	assert.Equal(t, start.Add(100*time.Minute), burst.FirstAt)
+	assert.Equal(t, start.Add(109*time.Minute), burst.LastAt)
+
+	assert.InDelta(t, 0.75, found[key{AnomalySubjectCustomer, 2, AnomalyRefundRatio}].Value, 1e-9)
+	assert.InDelta(t, 0.75, found[key{AnomalySubjectStaff, 5, AnomalyRefundRatio}].Value, 1e-9)
+
+	assert.Equal(t, AnomalyBurst, got[0].Kind)
+}
+
+func TestDetectAnomalies_BelowThresholds(t *testing.T) {
+	t.Parallel()
+
+	at := time.Date(2025, time.April, 1, 12, 0, 0, 0, time.UTC)
+	payments := []Payment{
+		{Type: PaymentTypePay, UserID: 1, Amount: 10000, CreatedAt: at},
+		{Type: PaymentTypeRefund, UserID: 1, Amount: -10000, CreatedAt: at.Add(time.Hour)},
+	}
+
+	got := DetectAnomalies(payments, AnomalyThresholds{
+		MinPayments:        2,
+		RefundRatio:        0.5,
+		MaxDiscountRepeats: 1,
+		BurstCount:         2,
+		BurstWindow:        time.Minute,
+	})
+
+	assert.Empty(t, got)
+}
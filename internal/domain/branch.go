@@ -2,7 +2,9 @@ package domain
 
 import (
 	"context"
+	"fmt"
 	"strconv"
+	"strings"
 )
 
 type BranchId int64
@@ -11,6 +13,25 @@ func BranchIdToStr(id BranchId) string {
 	return strconv.FormatInt(int64(id), 10)
 }
 
+// BranchIdsFromStr parses a comma separated terminal list like "9297,9341".
+func BranchIdsFromStr(s string) ([]BranchId, error) {
+	var ids []BranchId
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		id, err := strconv.ParseInt(part, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid branch id %q: %w", part, err)
+		}
+		ids = append(ids, BranchId(id))
+	}
+
+	return ids, nil
+}
+
 type Branch struct {
 	ID              BranchId `json:"id"`
 	Name            string   `json:"name"`
@@ -30,4 +51,5 @@ type BranchRepository interface {
 	Create(ctx context.Context, branch *Branch) (*Branch, error)
 	CheckIfBranchExist(ctx context.Context, id int64) (bool, error)
 	GetBranchesByCompanyName(ctx context.Context, companyName string) ([]BranchId, error)
+	GetByIds(ctx context.Context, ids []BranchId) ([]*Branch, error)
 }
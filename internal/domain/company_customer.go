@@ -2,22 +2,26 @@ package domain
 
 import (
 	"context"
+	"time"
 )
 
 type CompanyCustomer struct {
-	ID            int64   `json:"id"`
-	Company       string  `json:"company"`
-	UserID        int64   `json:"user_id"`
-	FullName      string  `json:"full_name"`
-	Phone         string  `json:"phone"`
-	Turnover      float64 `json:"turnover"`
-	LastVisitDate string  `json:"last_visit_date"`
-	VisitsCount   int64   `json:"visits_count"`
-	AverageBill   float64 `json:"average_bill"`
+	ID              int64     `json:"id"`
+	Company         string    `json:"company"`
+	UserID          int64     `json:"user_id"`
+	FullName        string    `json:"full_name"`
+	Phone           string    `json:"phone"`
+	NormalizedPhone string    `json:"phone_normalized,omitempty"`
+	Turnover        Money     `json:"turnover"`
+	LastVisitDate   time.Time `json:"last_visit_date"`
+	VisitsCount     int64     `json:"visits_count"`
+	AverageBill     Money     `json:"average_bill"`
 }
 
 type CompanyCustomerRepository interface {
 	Store(ctx context.Context, cc *CompanyCustomer) error
 	ExistsByCompanyUserId(ctx context.Context, company string, userId int64) (bool, error)
 	UpdateByCompanyUserId(ctx context.Context, cc *CompanyCustomer) error
+	// Snapshot copies the current stats of the company customers, all stamped with takenAt.
+	Snapshot(ctx context.Context, company string, takenAt time.Time) error
 }
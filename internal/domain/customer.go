@@ -12,16 +12,26 @@ func CustomerIdToStr(id CustomerID) string {
 }
 
 type Customer struct {
-	ID         CustomerID `json:"id"`
-	UserID     int        `json:"user_id"`
-	Phone      string     `json:"phone,omitempty"`
-	Birthday   string     `json:"birthday,omitempty"`
-	FullName   string     `json:"full_name,omitempty"`
-	OrderCount int64      `json:"orderCount,omitempty"`
+	ID              CustomerID `json:"id"`
+	UserID          int        `json:"user_id"`
+	Phone           string     `json:"phone,omitempty"`
+	NormalizedPhone string     `json:"phone_normalized,omitempty"`
+	Birthday        Date       `json:"birthday,omitempty"`
+	FullName        string     `json:"full_name,omitempty"`
+	OrderCount      int64      `json:"orderCount,omitempty"`
+}
+
+// PhoneDuplicate is a normalized phone shared by several user IDs
+// across customers and company_customers.
+type PhoneDuplicate struct {
+	Phone   string   `json:"phone"`
+	UserIDs []int64  `json:"user_ids"`
+	Sources []string `json:"sources"`
 }
 
 type CustomerRepository interface {
 	ExistsById(ctx context.Context, id CustomerID) (bool, error)
 	Create(ctx context.Context, customer *Customer) (*Customer, error)
 	FindById(ctx context.Context, id CustomerID) (Customer, error)
+	FindPhoneDuplicates(ctx context.Context) ([]PhoneDuplicate, error)
 }
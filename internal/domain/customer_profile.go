@@ -0,0 +1,34 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// CompanyStats are the latest company_customers figures of a user in one company.
+type CompanyStats struct {
+	Company       string    `json:"company"`
+	Turnover      Money     `json:"turnover"`
+	VisitsCount   int64     `json:"visits_count"`
+	AverageBill   Money     `json:"average_bill"`
+	LastVisitDate time.Time `json:"last_visit_date"`
+}
+
+// CustomerProfile is the customer_profiles read model joining customers and company_customers by user_id.
+type CustomerProfile struct {
+	UserID          int64          `json:"user_id"`
+	FullName        string         `json:"full_name"`
+	Phone           string         `json:"phone"`
+	NormalizedPhone string         `json:"phone_normalized"`
+	Birthday        Date           `json:"birthday"`
+	OrdersCount     int64          `json:"orders_count"`
+	CompaniesCount  int64          `json:"companies_count"`
+	Turnover        Money          `json:"turnover"`
+	VisitsCount     int64          `json:"visits_count"`
+	LastVisitDate   time.Time      `json:"last_visit_date"`
+	Companies       []CompanyStats `json:"companies"`
+}
+
+type CustomerProfileRepository interface {
+	FindByUserID(ctx context.Context, userID int64) (CustomerProfile, error)
+}
@@ -0,0 +1,136 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Date is a civil calendar date without time and zone, e.g. a birthday.
+// The zero value means the date is unknown and is stored as NULL.
+type Date struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+const dateLayout = "2006-01-02"
+
+// NewDate builds a Date, out of range values are normalized like time.Date does.
+func NewDate(year int, month time.Month, day int) Date {
+	return DateOf(time.Date(year, month, day, 0, 0, 0, 0, time.UTC))
+}
+
+// DateOf returns the date t falls on in its own location.
+func DateOf(t time.Time) Date {
+	y, m, d := t.Date()
+	return Date{Year: y, Month: m, Day: d}
+}
+
+func (d Date) IsZero() bool {
+	return d == Date{}
+}
+
+// In returns midnight of the date in loc.
+func (d Date) In(loc *time.Location) time.Time {
+	return time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, loc)
+}
+
+func isLeap(year int) bool {
+	return year%4 == 0 && (year%100 != 0 || year%400 == 0)
+}
+
+// AnniversaryIn returns the anniversary of d in the year. Feb 29 falls on Feb 28 in common years.
+func (d Date) AnniversaryIn(year int) Date {
+	if d.Month == time.February && d.Day == 29 && !isLeap(year) {
+		return Date{Year: year, Month: time.February, Day: 28}
+	}
+
+	return Date{Year: year, Month: d.Month, Day: d.Day}
+}
+
+// NextAnniversary returns the first anniversary of d on or after from.
+func (d Date) NextAnniversary(from Date) Date {
+	next := d.AnniversaryIn(from.Year)
+	if next.Before(from) {
+		next = d.AnniversaryIn(from.Year + 1)
+	}
+
+	return next
+}
+
+// AnniversaryWithin returns the next anniversary of d when it falls within the days starting at
+// today, today included, so days 1 is today only, and the days left until it.
+func (d Date) AnniversaryWithin(today Date, days int) (Date, int, bool) {
+	next := d.NextAnniversary(today)
+	daysLeft := today.DaysUntil(next)
+
+	return next, daysLeft, daysLeft < days
+}
+
+func (d Date) Before(other Date) bool {
+	return d.In(time.UTC).Before(other.In(time.UTC))
+}
+
+// DaysUntil counts calendar days from d to other, negative when other is earlier.
+func (d Date) DaysUntil(other Date) int {
+	return int(other.In(time.UTC).Sub(d.In(time.UTC)).Hours() / 24)
+}
+
+func (d Date) String() string {
+	if d.IsZero() {
+		return ""
+	}
+
+	return d.In(time.UTC).Format(dateLayout)
+}
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return []byte("null"), nil
+	}
+
+	return []byte(`"` + d.String() + `"`), nil
+}
+
+func (d *Date) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" || s == `""` {
+		*d = Date{}
+		return nil
+	}
+
+	t, err := time.Parse(`"`+dateLayout+`"`, s)
+	if err != nil {
+		return fmt.Errorf("parse date: %w", err)
+	}
+
+	*d = DateOf(t)
+
+	return nil
+}
+
+// DateValue implements pgtype.DateValuer.
+func (d Date) DateValue() (pgtype.Date, error) {
+	if d.IsZero() {
+		return pgtype.Date{}, nil
+	}
+
+	return pgtype.Date{Time: d.In(time.UTC), Valid: true}, nil
+}
+
+// ScanDate implements pgtype.DateScanner.
+func (d *Date) ScanDate(v pgtype.Date) error {
+	if !v.Valid {
+		*d = Date{}
+		return nil
+	}
+	if v.InfinityModifier != pgtype.Finite {
+		return fmt.Errorf("scan date: infinite date")
+	}
+
+	*d = DateOf(v.Time)
+
+	return nil
+}
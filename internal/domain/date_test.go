@@ -0,0 +1,109 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDate_NextAnniversary(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		birthday Date
+		from     Date
+		want     Date
+	}{
+		"later_this_year": {
+			birthday: NewDate(1990, time.May, 17),
+			from:     NewDate(2025, time.May, 1),
+			want:     NewDate(2025, time.May, 17),
+		},
+		"today": {
+			birthday: NewDate(1990, time.May, 17),
+			from:     NewDate(2025, time.May, 17),
+			want:     NewDate(2025, time.May, 17),
+		},
+		"year_wrap": {
+			birthday: NewDate(1990, time.January, 3),
+			from:     NewDate(2025, time.December, 25),
+			want:     NewDate(2026, time.January, 3),
+		},
+		"leap_day_common_year": {
+			birthday: NewDate(1992, time.February, 29),
+			from:     NewDate(2025, time.February, 20),
+			want:     NewDate(2025, time.February, 28),
+		},
+		"leap_day_leap_year": {
+			birthday: NewDate(1992, time.February, 29),
+			from:     NewDate(2028, time.February, 20),
+			want:     NewDate(2028, time.February, 29),
+		},
+		"leap_day_passed": {
+			birthday: NewDate(1992, time.February, 29),
+			from:     NewDate(2027, time.March, 1),
+			want:     NewDate(2028, time.February, 29),
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, tt.birthday.NextAnniversary(tt.from))
+		})
+	}
+}
+
+func TestDate_AnniversaryWithin(t *testing.T) {
+	t.Parallel()
+
+	birthday := NewDate(1990, time.May, 17)
+
+	tests := map[string]struct {
+		today        Date
+		days         int
+		wantDaysLeft int
+		wantOK       bool
+	}{
+		"today": {
+			today:        NewDate(2025, time.May, 17),
+			days:         1,
+			wantDaysLeft: 0,
+			wantOK:       true,
+		},
+		"last_day_of_window": {
+			today:        NewDate(2025, time.May, 4),
+			days:         14,
+			wantDaysLeft: 13,
+			wantOK:       true,
+		},
+		"day_after_window": {
+			today:        NewDate(2025, time.May, 3),
+			days:         14,
+			wantDaysLeft: 14,
+			wantOK:       false,
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			next, daysLeft, ok := birthday.AnniversaryWithin(tt.today, tt.days)
+			assert.Equal(t, NewDate(2025, time.May, 17), next)
+			assert.Equal(t, tt.wantDaysLeft, daysLeft)
+			assert.Equal(t, tt.wantOK, ok)
+		})
+	}
+}
+
+func TestDate_DaysUntil(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, 9, NewDate(2025, time.December, 25).DaysUntil(NewDate(2026, time.January, 3)))
+	assert.Equal(t, 0, NewDate(2025, time.May, 1).DaysUntil(NewDate(2025, time.May, 1)))
+}
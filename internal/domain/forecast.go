@@ -0,0 +1,22 @@
+package domain
+
+import (
+	"context"
+)
+
+// Forecast is the net revenue predicted on MadeOn for a day at the locations matched by Location,
+// Lower and Upper bound its prediction interval.
+type Forecast struct {
+	Location  string `json:"location"`
+	MadeOn    Date   `json:"made_on"`
+	Day       Date   `json:"day"`
+	Predicted Money  `json:"predicted"`
+	Lower     Money  `json:"lower"`
+	Upper     Money  `json:"upper"`
+	Model     string `json:"model"`
+}
+
+type ForecastRepository interface {
+	// Store replaces the forecasts of the same location, run day and predicted day.
+	Store(ctx context.Context, forecasts []Forecast) error
+}
@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Lifecycle stages.
+const (
+	LifecycleNew         = "new"
+	LifecycleActive      = "active"
+	LifecycleAtRisk      = "at_risk"
+	LifecycleLapsed      = "lapsed"
+	LifecycleReactivated = "reactivated"
+)
+
+const (
+	// LifecycleActiveWindow is how recent the last payment of an active customer is.
+	LifecycleActiveWindow = 30 * 24 * time.Hour
+	// LifecycleLapsedAfter is the gap after which a customer has lapsed.
+	LifecycleLapsedAfter = 90 * 24 * time.Hour
+)
+
+// LifecycleFacts are the payment timestamps a stage is derived from. LastReturnAt is the
+// latest payment made after a gap longer than LifecycleLapsedAfter, zero when there is none.
+type LifecycleFacts struct {
+	UserID       int64
+	FirstAt      time.Time
+	LastAt       time.Time
+	LastReturnAt time.Time
+}
+
+// LifecycleEvent is a change of a customer's stage, From is empty for the first one.
+type LifecycleEvent struct {
+	UserID    int64     `json:"user_id"`
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+// LifecycleTransitionCount is the number of customers moving From a stage To another on a day.
+type LifecycleTransitionCount struct {
+	Day       Date
+	From      string
+	To        string
+	Customers int64
+}
+
+type LifecycleRepository interface {
+	// Facts lists the payment timestamps of every customer with payments,
+	// LastAt is the last visit in company_customers when that is later.
+	Facts(ctx context.Context, lapsedAfter time.Duration) ([]LifecycleFacts, error)
+	CurrentStages(ctx context.Context) (map[int64]string, error)
+	// SetStage makes stage the current stage of the customer without recording an event.
+	SetStage(ctx context.Context, userID int64, stage string, since time.Time) error
+	// RecordEvent stores the event and makes its To the current stage of the customer.
+	RecordEvent(ctx context.Context, event *LifecycleEvent) error
+	TransitionCounts(ctx context.Context, from, to Date, zone string) ([]LifecycleTransitionCount, error)
+}
+
+// LifecycleStage classifies a customer at now:
+//   - new: the first payment is within LifecycleActiveWindow,
+//   - reactivated: came back after a lapse within LifecycleActiveWindow,
+//   - active: paid within LifecycleActiveWindow,
+//   - at_risk: last paid within LifecycleLapsedAfter,
+//   - lapsed: otherwise.
+func LifecycleStage(f LifecycleFacts, now time.Time) string {
+	switch {
+	case now.Sub(f.FirstAt) <= LifecycleActiveWindow:
+		return LifecycleNew
+	case !f.LastReturnAt.IsZero() && now.Sub(f.LastReturnAt) <= LifecycleActiveWindow:
+		return LifecycleReactivated
+	case now.Sub(f.LastAt) <= LifecycleActiveWindow:
+		return LifecycleActive
+	case now.Sub(f.LastAt) <= LifecycleLapsedAfter:
+		return LifecycleAtRisk
+	default:
+		return LifecycleLapsed
+	}
+}
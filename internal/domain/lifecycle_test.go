@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLifecycleStage(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, time.June, 1, 12, 0, 0, 0, time.UTC)
+	daysAgo := func(days int) time.Time { return now.AddDate(0, 0, -days) }
+
+	tests := map[string]struct {
+		facts LifecycleFacts
+		want  string
+	}{
+		"new": {
+			facts: LifecycleFacts{FirstAt: daysAgo(10), LastAt: daysAgo(2)},
+			want:  LifecycleNew,
+		},
+		"active": {
+			facts: LifecycleFacts{FirstAt: daysAgo(200), LastAt: daysAgo(30)},
+			want:  LifecycleActive,
+		},
+		"at_risk": {
+			facts: LifecycleFacts{FirstAt: daysAgo(200), LastAt: daysAgo(31)},
+			want:  LifecycleAtRisk,
+		},
+		"lapsed": {
+			facts: LifecycleFacts{FirstAt: daysAgo(200), LastAt: daysAgo(91)},
+			want:  LifecycleLapsed,
+		},
+		"reactivated": {
+			facts: LifecycleFacts{FirstAt: daysAgo(300), LastAt: daysAgo(1), LastReturnAt: daysAgo(20)},
+			want:  LifecycleReactivated,
+		},
+		"active_again_after_return": {
+			facts: LifecycleFacts{FirstAt: daysAgo(300), LastAt: daysAgo(3), LastReturnAt: daysAgo(45)},
+			want:  LifecycleActive,
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, LifecycleStage(tt.facts, now))
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package domain
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Money is an amount in minor units (tiyn, 1/100 of a tenge).
+type Money int64
+
+// moneyExp is the decimal exponent of a minor unit.
+const moneyExp = -2
+
+var ErrInvalidMoney = errors.New("invalid money amount")
+
+// ParseMoney parses a decimal amount in tenge ("1234", "1234.5", "-0.005")
+// without going through float64. Digits beyond tiyn are rounded half away from zero.
+func ParseMoney(s string) (Money, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, ErrInvalidMoney
+	}
+
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidMoney, s)
+	}
+
+	return moneyFromRat(r)
+}
+
+// MustParseMoney is like ParseMoney but panics on error.
+func MustParseMoney(s string) Money {
+	m, err := ParseMoney(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return m
+}
+
+func moneyFromRat(tenge *big.Rat) (Money, error) {
+	return roundMinor(new(big.Rat).Mul(tenge, big.NewRat(100, 1)))
+}
+
+// roundMinor rounds an amount given in minor units half away from zero.
+func roundMinor(r *big.Rat) (Money, error) {
+	num := new(big.Int).Abs(r.Num())
+	q, rem := new(big.Int).QuoRem(num, r.Denom(), new(big.Int))
+
+	if rem.Lsh(rem, 1).Cmp(r.Denom()) >= 0 {
+		q.Add(q, big.NewInt(1))
+	}
+	if r.Sign() < 0 {
+		q.Neg(q)
+	}
+
+	if !q.IsInt64() {
+		return 0, fmt.Errorf("%w: %s out of range", ErrInvalidMoney, r.FloatString(0))
+	}
+
+	return Money(q.Int64()), nil
+}
+
+// String formats the amount in tenge with two decimals, e.g. "1234.50".
+func (m Money) String() string {
+	sign := ""
+	v := int64(m)
+	if v < 0 {
+		sign = "-"
+	}
+
+	abs := new(big.Int).Abs(big.NewInt(v))
+	q, r := new(big.Int).QuoRem(abs, big.NewInt(100), new(big.Int))
+
+	return fmt.Sprintf("%s%s.%02d", sign, q.String(), r.Int64())
+}
+
+// Float64 returns the amount in tenge. Use it only for ratios and presentation.
+func (m Money) Float64() float64 {
+	return float64(m) / 100
+}
+
+// MulRat multiplies the amount by num/den with half away from zero rounding.
+func (m Money) MulRat(num, den int64) Money {
+	if den == 0 {
+		return 0
+	}
+
+	r := new(big.Rat).SetFrac(big.NewInt(int64(m)), big.NewInt(den))
+	res, err := roundMinor(r.Mul(r, new(big.Rat).SetInt64(num)))
+	if err != nil {
+		return 0
+	}
+
+	return res
+}
+
+// UnmarshalJSON accepts JSON numbers, numeric strings and null.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		*m = 0
+		return nil
+	}
+
+	s := string(data)
+	if len(data) > 0 && data[0] == '"' {
+		unquoted, err := strconv.Unquote(s)
+		if err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidMoney, s)
+		}
+		if unquoted == "" {
+			*m = 0
+			return nil
+		}
+		s = unquoted
+	}
+
+	parsed, err := ParseMoney(s)
+	if err != nil {
+		return err
+	}
+
+	*m = parsed
+
+	return nil
+}
+
+// MarshalJSON encodes the amount as a JSON number in tenge.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// NumericValue implements pgtype.NumericValuer so Money is stored in NUMERIC columns as tenge.
+func (m Money) NumericValue() (pgtype.Numeric, error) {
+	return pgtype.Numeric{Int: big.NewInt(int64(m)), Exp: moneyExp, Valid: true}, nil
+}
+
+// ScanNumeric implements pgtype.NumericScanner. NULL is scanned as zero.
+func (m *Money) ScanNumeric(v pgtype.Numeric) error {
+	if !v.Valid {
+		*m = 0
+		return nil
+	}
+	if v.NaN || v.InfinityModifier != pgtype.Finite {
+		return fmt.Errorf("%w: non-finite numeric", ErrInvalidMoney)
+	}
+
+	r := new(big.Rat).SetInt(v.Int)
+	exp := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(absInt32(v.Exp))), nil)
+	if v.Exp < 0 {
+		r.Quo(r, new(big.Rat).SetInt(exp))
+	} else {
+		r.Mul(r, new(big.Rat).SetInt(exp))
+	}
+
+	parsed, err := moneyFromRat(r)
+	if err != nil {
+		return err
+	}
+
+	*m = parsed
+
+	return nil
+}
+
+func absInt32(v int32) int32 {
+	if v < 0 {
+		return -v
+	}
+
+	return v
+}
@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMoney(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		in      string
+		want    Money
+		wantErr bool
+	}{
+		"integer":          {in: "1234", want: 123400},
+		"one_decimal":      {in: "1234.5", want: 123450},
+		"round_half_up":    {in: "0.005", want: 1},
+		"round_down":       {in: "0.0049", want: 0},
+		"negative_half":    {in: "-0.005", want: -1},
+		"float_drift":      {in: "0.29", want: 29},
+		"exponent":         {in: "1.5e3", want: 150000},
+		"empty":            {in: "", wantErr: true},
+		"not_a_number":     {in: "12a", wantErr: true},
+		"surrounding_ws":   {in: " 10.10 ", want: 1010},
+		"many_fractionals": {in: "19.999999", want: 2000},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParseMoney(tt.in)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidMoney)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMoney_String(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "0.00", Money(0).String())
+	assert.Equal(t, "12.05", Money(1205).String())
+	assert.Equal(t, "-0.50", Money(-50).String())
+}
+
+func TestMoney_UnmarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	var v struct {
+		Amount   Money `json:"amount"`
+		Discount Money `json:"discount"`
+		Missing  Money `json:"missing"`
+	}
+
+	err := json.Unmarshal([]byte(`{"amount": 4590.995, "discount": "100.1", "missing": null}`), &v)
+	require.NoError(t, err)
+
+	assert.Equal(t, Money(459100), v.Amount)
+	assert.Equal(t, Money(10010), v.Discount)
+	assert.Equal(t, Money(0), v.Missing)
+}
+
+func TestMoney_ScanNumeric(t *testing.T) {
+	t.Parallel()
+
+	var m Money
+
+	require.NoError(t, m.ScanNumeric(pgtype.Numeric{Int: big.NewInt(123456), Exp: -3, Valid: true}))
+	assert.Equal(t, Money(12346), m)
+
+	require.NoError(t, m.ScanNumeric(pgtype.Numeric{Int: big.NewInt(5), Exp: 2, Valid: true}))
+	assert.Equal(t, Money(50000), m)
+
+	require.NoError(t, m.ScanNumeric(pgtype.Numeric{}))
+	assert.Equal(t, Money(0), m)
+
+	n, err := Money(1999).NumericValue()
+	require.NoError(t, err)
+	assert.Equal(t, pgtype.Numeric{Int: big.NewInt(1999), Exp: -2, Valid: true}, n)
+}
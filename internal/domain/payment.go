@@ -1,22 +1,68 @@
 package domain
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
+// Payment is a transaction at a location. A refund's RefundOf is the payment it reverses,
+// zero while unmatched, and a payment's RefundedAmount sums the refunds matched to it.
 type Payment struct {
-	ID                PaymentID `json:"id"`
-	Type              string    `json:"type"`
-	CreatedBy         int64     `json:"created_by,omitempty"`
-	Amount            int64     `json:"amount,omitempty"`
-	DiscountAmount    int64     `json:"discount_amount,omitempty"`
-	CreatedAt         string    `json:"created_at,omitempty"`
-	LocationTitle     string    `json:"location_title,omitempty"`
-	LocationPartnerID string    `json:"location_partner_id,omitempty"`
+	ID                PaymentID   `json:"id"`
+	UserID            int64       `json:"user_id,omitempty"`
+	Type              PaymentType `json:"type"`
+	CreatedBy         int64       `json:"created_by,omitempty"`
+	Amount            Money       `json:"amount,omitempty"`
+	DiscountAmount    Money       `json:"discount_amount,omitempty"`
+	CreatedAt         time.Time   `json:"created_at,omitempty"`
+	LocationTitle     string      `json:"location_title,omitempty"`
+	LocationPartnerID string      `json:"location_partner_id,omitempty"`
+	BranchID          BranchId    `json:"branch_id,omitempty"`
+	LocationID        string      `json:"location_id,omitempty"`
+	RefundOf          PaymentID   `json:"refund_of,omitempty"`
+	RefundedAmount    Money       `json:"refunded_amount,omitempty"`
 }
 
 type PaymentID int64
 
+// PaymentType is the transaction type reported by the API.
+type PaymentType string
+
+const (
+	PaymentTypePay    PaymentType = "pay"
+	PaymentTypeRefund PaymentType = "refund"
+)
+
+// NetAmount is what the payment brought after the refunds matched to it.
+//
+// It is the net revenue rule every report follows: a payment counts its NetAmount at the time it was
+// made, a refund matched to no payment is subtracted at the time it was made and a matched refund
+// counts nowhere else, its amount is already part of its payment's RefundedAmount.
+func (p *Payment) NetAmount() Money {
+	return p.Amount - p.RefundedAmount
+}
+
+// RefundAmount is the refunded sum, the API may report refunds with either sign.
+func (p *Payment) RefundAmount() Money {
+	if p.Amount < 0 {
+		return -p.Amount
+	}
+
+	return p.Amount
+}
+
 type PaymentRepository interface {
 	ExistsById(ctx context.Context, id PaymentID) (bool, error)
 	FindById(ctx context.Context, id PaymentID) (*Payment, error)
 	Create(ctx context.Context, payment *Payment) (*Payment, error)
+	// FillUserID sets the user of a payment stored without one.
+	FillUserID(ctx context.Context, id PaymentID, userID int64) error
+	FindByUserID(ctx context.Context, userID int64, limit int) ([]Payment, error)
+	FindBetween(ctx context.Context, since, until time.Time) ([]Payment, error)
+	// FindUnlinkedRefunds lists the refunds made since the time that aren't linked to a payment yet.
+	FindUnlinkedRefunds(ctx context.Context, since time.Time) ([]Payment, error)
+	// FindRefundCandidates lists the payments of the user made between since and until.
+	FindRefundCandidates(ctx context.Context, userID int64, since, until time.Time) ([]Payment, error)
+	// LinkRefund points the refund to the payment and adds amount to the payment's refunded amount.
+	LinkRefund(ctx context.Context, refundID, paymentID PaymentID, amount Money) error
 }
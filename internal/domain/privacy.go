@@ -0,0 +1,23 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Erasure is the audit record of a user purged on a right-to-erasure request.
+type Erasure struct {
+	UserID                  int64     `json:"user_id"`
+	ErasedAt                time.Time `json:"erased_at"`
+	CustomersDeleted        int64     `json:"customers_deleted"`
+	CompanyCustomersDeleted int64     `json:"company_customers_deleted"`
+	PaymentsAnonymized      int64     `json:"payments_anonymized"`
+	ReviewsDeleted          int64     `json:"reviews_deleted"`
+}
+
+type PrivacyRepository interface {
+	// EraseUser deletes the PII of the user and unlinks their payments, keeping the amounts.
+	EraseUser(ctx context.Context, userID int64) (Erasure, error)
+	RecordErasure(ctx context.Context, erasure *Erasure) error
+	ErasedUserIDs(ctx context.Context) ([]int64, error)
+}
@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	// RefundWindow is how long after a payment a refund may still reverse it.
+	RefundWindow = 30 * 24 * time.Hour
+	// RefundRetryWindow is how long an unmatched refund is retried on later syncs. Its payment may be
+	// synced late, but a refund still unmatched after that, e.g. one split across several payments, stays unlinked.
+	RefundRetryWindow = RefundWindow + 7*24*time.Hour
+)
+
+// MatchRefund picks the payment the refund most likely reverses. The transaction
+// carries no reference to the original payment, so a candidate must be a payment of the
+// same customer at the same location made within RefundWindow before the refund, with an
+// unrefunded remainder covering the refund. A remainder equal to the refund wins over a
+// larger one, ties go to the latest payment. It returns nil when nothing qualifies.
+func MatchRefund(refund Payment, candidates []Payment) *Payment {
+	amount := refund.RefundAmount()
+
+	var best *Payment
+	bestExact := false
+	for i := range candidates {
+		c := &candidates[i]
+		if c.Type != PaymentTypePay || c.ID == refund.ID || c.UserID != refund.UserID || !sameLocation(*c, refund) {
+			continue
+		}
+		if c.CreatedAt.After(refund.CreatedAt) || refund.CreatedAt.Sub(c.CreatedAt) > RefundWindow {
+			continue
+		}
+
+		remainder := c.NetAmount()
+		if remainder < amount {
+			continue
+		}
+
+		exact := remainder == amount
+		switch {
+		case best == nil,
+			exact && !bestExact,
+			exact == bestExact && c.CreatedAt.After(best.CreatedAt):
+			best, bestExact = c, exact
+		}
+	}
+
+	return best
+}
+
+func sameLocation(a, b Payment) bool {
+	if a.LocationID != "" && b.LocationID != "" {
+		return a.LocationID == b.LocationID
+	}
+
+	return strings.EqualFold(strings.TrimSpace(a.LocationTitle), strings.TrimSpace(b.LocationTitle))
+}
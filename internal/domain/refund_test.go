@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchRefund(t *testing.T) {
+	t.Parallel()
+
+	at := time.Date(2025, time.March, 10, 18, 0, 0, 0, time.UTC)
+	pay := func(id PaymentID, amount, refunded Money, ago time.Duration) Payment {
+		return Payment{
+			ID:             id,
+			UserID:         7,
+			Type:           PaymentTypePay,
+			Amount:         amount,
+			RefundedAmount: refunded,
+			CreatedAt:      at.Add(-ago),
+			LocationTitle:  "Dostyk",
+		}
+	}
+	refund := Payment{ID: 100, UserID: 7, Type: PaymentTypeRefund, Amount: -150000, CreatedAt: at, LocationTitle: "dostyk "}
+
+	tests := map[string]struct {
+		candidates []Payment
+		want       PaymentID
+	}{
+		"exact_over_latest": {
+			candidates: []Payment{pay(1, 150000, 0, 48*time.Hour), pay(2, 300000, 0, time.Hour)},
+			want:       1,
+		},
+		"latest_of_partial": {
+			candidates: []Payment{pay(1, 200000, 0, 48*time.Hour), pay(2, 300000, 0, time.Hour)},
+			want:       2,
+		},
+		"remainder_after_refunds": {
+			candidates: []Payment{pay(1, 300000, 150000, 48*time.Hour), pay(2, 300000, 0, time.Hour)},
+			want:       1,
+		},
+		"already_refunded": {
+			candidates: []Payment{pay(1, 150000, 100000, time.Hour)},
+		},
+		"outside_window": {
+			candidates: []Payment{pay(1, 150000, 0, RefundWindow+time.Hour)},
+		},
+		"after_refund": {
+			candidates: []Payment{pay(1, 150000, 0, -time.Hour)},
+		},
+		"other_location": {
+			candidates: []Payment{func() Payment { p := pay(1, 150000, 0, time.Hour); p.LocationTitle = "Abaya"; return p }()},
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got := MatchRefund(refund, tt.candidates)
+			if tt.want == 0 {
+				assert.Nil(t, got)
+				return
+			}
+
+			if assert.NotNil(t, got) {
+				assert.Equal(t, tt.want, got.ID)
+			}
+		})
+	}
+}
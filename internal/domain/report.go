@@ -0,0 +1,133 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// CohortCell is the number of customers of a first-payment month cohort
+// who paid again Offset months later. Offset 0 is the cohort size.
+type CohortCell struct {
+	Cohort    Date
+	Offset    int
+	Customers int64
+}
+
+// ChurnCandidate is a company customer inactive since before the cutoff.
+// IntervalDays is the mean gap between their payments, nil with fewer than two payments.
+// VisitIntervalDays is the mean gap seen across the company_customers syncs: the days between the
+// earliest and latest recorded last visit over the visits made in between, nil when no sync saw a new visit.
+type ChurnCandidate struct {
+	UserID            int64
+	FullName          string
+	Phone             string
+	Turnover          Money
+	VisitsCount       int64
+	AverageBill       Money
+	LastVisit         time.Time
+	IntervalDays      *float64
+	VisitIntervalDays *float64
+}
+
+// Sources of the typical interval a churn candidate's gap is measured against.
+const (
+	ChurnIntervalPayments = "payments"
+	ChurnIntervalVisits   = "company_customers"
+	ChurnIntervalInactive = "inactive_days"
+)
+
+// BirthdayCandidate is a company customer with a known birthday.
+type BirthdayCandidate struct {
+	UserID      int64
+	FullName    string
+	Phone       string
+	Birthday    Date
+	VisitsCount int64
+	Turnover    Money
+}
+
+// DiscountCell sums the payments of a location in a month, Amount is the net revenue, see Payment.NetAmount.
+// Customers are split by whether they got a discount in the month, returned ones paid at the location
+// again within the window.
+// LocationID is empty for payments that couldn't be attributed to a location, they are told apart by title.
+type DiscountCell struct {
+	LocationID          string
+	Location            string
+	Month               Date
+	Payments            int64
+	DiscountedPayments  int64
+	Amount              Money
+	Discounts           Money
+	DiscountedCustomers int64
+	DiscountedReturned  int64
+	OtherCustomers      int64
+	OtherReturned       int64
+}
+
+// OverlapCustomer is a customer with stats in both compared companies, A and B.
+type OverlapCustomer struct {
+	UserID     int64
+	FullName   string
+	Phone      string
+	TurnoverA  Money
+	TurnoverB  Money
+	VisitsA    int64
+	VisitsB    int64
+	LastVisitA time.Time
+	LastVisitB time.Time
+}
+
+// SwitchCandidate holds the last visits of a customer to companies A and B as of the two latest
+// snapshots of each company. A zero time means the customer had no visit to the company then.
+type SwitchCandidate struct {
+	UserID         int64
+	FullName       string
+	Phone          string
+	PrevLastVisitA time.Time
+	PrevLastVisitB time.Time
+	LastVisitA     time.Time
+	LastVisitB     time.Time
+}
+
+// HeatmapCell sums the payments made at an ISO weekday (1 is Monday) and hour of the day.
+// Revenue is the net revenue, see Payment.NetAmount.
+type HeatmapCell struct {
+	Weekday  int
+	Hour     int
+	Payments int64
+	Revenue  Money
+}
+
+// StaffActivity sums the transactions a staff member made at the location in a period.
+// Revenue is the paid amount before refunds, which show up under the staff member making them.
+type StaffActivity struct {
+	Period       Date
+	StaffID      int64
+	StaffName    string
+	Payments     int64
+	Revenue      Money
+	Discounts    Money
+	Refunds      int64
+	RefundAmount Money
+}
+
+// ReportRepository runs the analytical queries behind the report commands.
+// Timestamps are bucketed in the zone passed to each query.
+type ReportRepository interface {
+	CohortCounts(ctx context.Context, branchIDs []BranchId, zone string) ([]CohortCell, error)
+	ChurnCandidates(
+		ctx context.Context,
+		company string,
+		branchIDs []BranchId,
+		inactiveSince time.Time,
+		minTurnover Money,
+	) ([]ChurnCandidate, error)
+	BirthdayCandidates(ctx context.Context, company string) ([]BirthdayCandidate, error)
+	DiscountCells(ctx context.Context, from, to Date, zone string, returnDays int) ([]DiscountCell, error)
+	OverlapCustomers(ctx context.Context, companyA, companyB string) ([]OverlapCustomer, error)
+	SwitchCandidates(ctx context.Context, companyA, companyB string) ([]SwitchCandidate, error)
+	// HeatmapCells matches the location by ID or by a part of its title.
+	HeatmapCells(ctx context.Context, location string, from, to Date, zone string) ([]HeatmapCell, error)
+	// StaffActivity matches the location like HeatmapCells and groups by GroupByDay, GroupByWeek or GroupByMonth.
+	StaffActivity(ctx context.Context, location string, from, to Date, zone, groupBy string) ([]StaffActivity, error)
+}
@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"context"
+)
+
+// Revenue grouping periods.
+const (
+	GroupByDay   = "day"
+	GroupByWeek  = "week"
+	GroupByMonth = "month"
+)
+
+// RevenueRow is the revenue of one location over a period. Gross is the sum of payments,
+// Net is the net revenue, see Payment.NetAmount, and RefundAmount the difference: the refunds
+// matched to the period's payments and the unmatched refunds made in the period.
+// Discounts were given on top of Gross.
+// LocationID is empty for payments that couldn't be attributed to a location.
+type RevenueRow struct {
+	Period       Date
+	LocationID   string
+	PartnerID    string
+	PartnerName  string
+	Location     string
+	Payments     int64
+	Refunds      int64
+	Gross        Money
+	Discounts    Money
+	RefundAmount Money
+	Net          Money
+}
+
+type RevenueRepository interface {
+	// RefreshDailyRevenue recomputes daily_revenue for the days from the given one on.
+	// Linking a refund changes the net revenue of its payment's day, which may be well before the synced range.
+	RefreshDailyRevenue(ctx context.Context, from Date, zone string) error
+	Revenue(ctx context.Context, from, to Date, groupBy string) ([]RevenueRow, error)
+	// LocationDailyRevenue lists the daily net revenue of the locations with the ID or whose title
+	// contains location, an empty location matches all of them.
+	LocationDailyRevenue(ctx context.Context, location string, from, to Date) ([]LocationRevenue, error)
+}
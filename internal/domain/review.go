@@ -0,0 +1,22 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Review is the feedback a customer left for a payment.
+type Review struct {
+	PaymentID PaymentID       `json:"payment_id"`
+	UserID    int64           `json:"user_id"`
+	Rating    int             `json:"rating"`
+	Comment   string          `json:"comment,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+	Raw       json.RawMessage `json:"-"`
+}
+
+type ReviewRepository interface {
+	Store(ctx context.Context, review *Review) error
+	FindByUserID(ctx context.Context, userID int64, limit int) ([]Review, error)
+}
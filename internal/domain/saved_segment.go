@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// Segment membership events.
+const (
+	SegmentMemberEntered = "entered"
+	SegmentMemberLeft    = "left"
+)
+
+// SavedSegment is a named segment filter with the size of its latest evaluation,
+// EvaluatedAt is zero until the first one.
+type SavedSegment struct {
+	ID          int64     `json:"id"`
+	Name        string    `json:"name"`
+	Filter      string    `json:"filter"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	EvaluatedAt time.Time `json:"evaluated_at,omitempty"`
+	Members     int       `json:"members"`
+}
+
+// SegmentCondition is a parsed segment filter. SQL renders it as a condition over the
+// customer_company_stats view aliased s and the customer_profiles view aliased p,
+// with every literal bound as an argument.
+type SegmentCondition interface {
+	SQL(now time.Time) (string, []any)
+}
+
+// SegmentMemberEvent is a customer entering or leaving a saved segment on its evaluation at At.
+type SegmentMemberEvent struct {
+	SegmentID int64     `json:"segment_id"`
+	UserID    int64     `json:"user_id"`
+	Event     string    `json:"event"`
+	At        time.Time `json:"at"`
+}
+
+// SegmentMemberChange is a membership event with the customer's contact for targeting.
+type SegmentMemberChange struct {
+	SegmentMemberEvent
+	FullName string `json:"full_name"`
+	Phone    string `json:"phone"`
+}
+
+type SavedSegmentRepository interface {
+	// Save creates the segment or replaces the filter of the segment with the name,
+	// a changed filter drops the members so the next evaluation starts over.
+	Save(ctx context.Context, name, filter string, now time.Time) (*SavedSegment, error)
+	FindByName(ctx context.Context, name string) (*SavedSegment, error)
+	List(ctx context.Context) ([]SavedSegment, error)
+	// Match lists the IDs of the users matching the condition at now.
+	Match(ctx context.Context, cond SegmentCondition, now time.Time) ([]int64, error)
+	// MatchPhones lists the distinct normalized phones of the users matching the condition at now.
+	MatchPhones(ctx context.Context, cond SegmentCondition, now time.Time) ([]string, error)
+	Members(ctx context.Context, segmentID int64) ([]int64, error)
+	RecordMemberEvents(ctx context.Context, events []SegmentMemberEvent) error
+	// MemberChanges lists the events of the segment between the days in the zone, of any event when event is empty.
+	MemberChanges(ctx context.Context, segmentID int64, event string, from, to Date, zone string) ([]SegmentMemberChange, error)
+	// ChangePhones lists the distinct normalized phones of the users with the event on the evaluation at at.
+	ChangePhones(ctx context.Context, segmentID int64, event string, at time.Time) ([]string, error)
+	// ReplaceMembers stores the users as the membership of the segment evaluated at evaluatedAt.
+	ReplaceMembers(ctx context.Context, segmentID int64, userIDs []int64, evaluatedAt time.Time) error
+}
+
+// DiffSegmentMembers returns the users of current missing from previous and the users of previous missing
+// from current, both sorted.
+func DiffSegmentMembers(previous, current []int64) (entered, left []int64) {
+	was := make(map[int64]bool, len(previous))
+	for _, id := range previous {
+		was[id] = true
+	}
+
+	is := make(map[int64]bool, len(current))
+	for _, id := range current {
+		if is[id] {
+			continue
+		}
+		is[id] = true
+		if !was[id] {
+			entered = append(entered, id)
+		}
+	}
+
+	for id := range was {
+		if !is[id] {
+			left = append(left, id)
+		}
+	}
+
+	sort.Slice(entered, func(i, j int) bool { return entered[i] < entered[j] })
+	sort.Slice(left, func(i, j int) bool { return left[i] < left[j] })
+
+	return entered, left
+}
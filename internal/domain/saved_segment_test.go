@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffSegmentMembers(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		previous    []int64
+		current     []int64
+		wantEntered []int64
+		wantLeft    []int64
+	}{
+		"first evaluation": {
+			current:     []int64{3, 1, 2},
+			wantEntered: []int64{1, 2, 3},
+		},
+		"unchanged": {
+			previous: []int64{1, 2},
+			current:  []int64{2, 1},
+		},
+		"entered and left": {
+			previous:    []int64{1, 2, 5, 4},
+			current:     []int64{2, 6, 3, 3},
+			wantEntered: []int64{3, 6},
+			wantLeft:    []int64{1, 4, 5},
+		},
+		"emptied": {
+			previous: []int64{7},
+			wantLeft: []int64{7},
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			entered, left := DiffSegmentMembers(tt.previous, tt.current)
+			assert.Equal(t, tt.wantEntered, entered)
+			assert.Equal(t, tt.wantLeft, left)
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Sources the RFM figures are computed from.
+const (
+	SegmentSourcePayments         = "payments"
+	SegmentSourceCompanyCustomers = "company_customers"
+)
+
+// RFMStats are the recency, frequency and monetary figures of one customer.
+type RFMStats struct {
+	UserID    int64
+	LastVisit time.Time
+	Frequency int64
+	Monetary  Money
+}
+
+// CustomerSegment is the RFM segment of a customer on a run date.
+type CustomerSegment struct {
+	RunDate     Date   `json:"run_date"`
+	Company     string `json:"company"`
+	UserID      int64  `json:"user_id"`
+	RecencyDays int    `json:"recency_days"`
+	Frequency   int64  `json:"frequency"`
+	Monetary    Money  `json:"monetary"`
+	RScore      int    `json:"r_score"`
+	FScore      int    `json:"f_score"`
+	MScore      int    `json:"m_score"`
+	Segment     string `json:"segment"`
+	Source      string `json:"source"`
+}
+
+type CustomerSegmentRepository interface {
+	RFMStatsFromPayments(ctx context.Context, branchIDs []BranchId) ([]RFMStats, error)
+	RFMStatsFromCompanyCustomers(ctx context.Context, company string) ([]RFMStats, error)
+	// ReplaceRun stores the segments of a run replacing an earlier run of the same day.
+	ReplaceRun(ctx context.Context, runDate Date, company string, segments []CustomerSegment) error
+	// SegmentPhones lists the distinct normalized phones of the customers in the segment on the
+	// latest run of the company, of every company's latest run when company is empty.
+	SegmentPhones(ctx context.Context, company, segment string) ([]string, error)
+}
@@ -0,0 +1,20 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Staff is an employee seen as payments.created_by. The API exposes only the ID,
+// Name stays empty until it is filled in by hand.
+type Staff struct {
+	ID          int64     `json:"id"`
+	Name        string    `json:"name,omitempty"`
+	FirstSeenAt time.Time `json:"first_seen_at"`
+	LastSeenAt  time.Time `json:"last_seen_at"`
+}
+
+type StaffRepository interface {
+	// Touch records that the staff member made a transaction at seenAt, creating them when new.
+	Touch(ctx context.Context, id int64, seenAt time.Time) error
+}
@@ -0,0 +1,91 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/ibookerke/choco_parser_go/internal/domain"
+	"github.com/ibookerke/choco_parser_go/internal/pkg/chocotime"
+	"github.com/ibookerke/choco_parser_go/internal/pkg/phone"
+)
+
+// All lists the data migrations in the order of their schema versions.
+//
+//nolint:gochecknoglobals
+var All = []Data{
+	{Version: 7, Name: "customers_birthday_to_date", Up: customersBirthdayToDate},
+	{Version: 9, Name: "phone_normalized", Up: phoneNormalized},
+}
+
+// customersBirthdayToDate fills customers.birthday_date from the free-form birthday strings.
+// Values in unknown formats are logged and left NULL.
+func customersBirthdayToDate(ctx context.Context, tx pgx.Tx, logger *slog.Logger) error {
+	rows, err := tx.Query(ctx, `SELECT id, birthday FROM customers
+		WHERE birthday IS NOT NULL AND birthday <> '' AND birthday_date IS NULL`)
+	if err != nil {
+		return fmt.Errorf("select birthdays: %w", err)
+	}
+
+	birthdays := make(map[int64]domain.Date)
+	for rows.Next() {
+		var (
+			id  int64
+			raw string
+		)
+		if err := rows.Scan(&id, &raw); err != nil {
+			rows.Close()
+			return fmt.Errorf("scan birthday: %w", err)
+		}
+
+		y, m, d, err := chocotime.ParseDate(raw)
+		if err != nil {
+			logger.Warn("unparsable birthday", "customer_id", id, "err", err)
+			continue
+		}
+		birthdays[id] = domain.NewDate(y, m, d)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("read birthdays: %w", err)
+	}
+
+	for id, birthday := range birthdays {
+		if _, err := tx.Exec(ctx, `UPDATE customers SET birthday_date = $1 WHERE id = $2`, birthday, id); err != nil {
+			return fmt.Errorf("update birthday of customer %d: %w", id, err)
+		}
+	}
+
+	return nil
+}
+
+// phoneNormalized backfills phone_normalized of customers and company_customers.
+func phoneNormalized(ctx context.Context, tx pgx.Tx, _ *slog.Logger) error {
+	for _, table := range []string{"customers", "company_customers"} {
+		rows, err := tx.Query(ctx, `SELECT DISTINCT phone FROM `+table+` WHERE phone IS NOT NULL AND phone <> ''`)
+		if err != nil {
+			return fmt.Errorf("select %s phones: %w", table, err)
+		}
+
+		phones, err := pgx.CollectRows(rows, pgx.RowTo[string])
+		if err != nil {
+			return fmt.Errorf("scan %s phones: %w", table, err)
+		}
+
+		for _, raw := range phones {
+			normalized, ok := phone.Normalize(raw)
+			if !ok {
+				continue
+			}
+
+			_, err := tx.Exec(ctx, `UPDATE `+table+` SET phone_normalized = $1 WHERE phone = $2`, normalized, raw)
+			if err != nil {
+				return fmt.Errorf("update %s phone: %w", table, err)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,118 @@
+// Package migration runs the SQL schema migrations together with Go data migrations
+// that have to be executed between two schema versions.
+package migration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Data is a Go data migration executed right after the schema migration with the same Version.
+type Data struct {
+	Version uint
+	Name    string
+	Up      func(ctx context.Context, tx pgx.Tx, logger *slog.Logger) error
+}
+
+const (
+	createDataMigrationsTable = `CREATE TABLE IF NOT EXISTS data_migrations (
+		version BIGINT PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`
+
+	dataMigrationApplied = `SELECT EXISTS (
+		SELECT 1 FROM data_migrations WHERE version = $1)`
+
+	dataMigrationInsert = `INSERT INTO data_migrations (version, name) VALUES ($1, $2)`
+)
+
+// Up migrates the schema to the latest version, stopping at every data migration version
+// to run the Go step in its own transaction. Applied data migrations are recorded in data_migrations.
+func Up(ctx context.Context, m *migrate.Migrate, pool *pgxpool.Pool, logger *slog.Logger, data []Data) error {
+	if _, err := pool.Exec(ctx, createDataMigrationsTable); err != nil {
+		return fmt.Errorf("create data_migrations table: %w", err)
+	}
+
+	sorted := make([]Data, len(data))
+	copy(sorted, data)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+
+	for _, d := range sorted {
+		current, err := schemaVersion(m)
+		if err != nil {
+			return err
+		}
+
+		if current < d.Version {
+			if err := m.Migrate(d.Version); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+				return fmt.Errorf("migrate to version %d: %w", d.Version, err)
+			}
+		}
+
+		if err := runData(ctx, pool, logger, d, current > d.Version); err != nil {
+			return err
+		}
+	}
+
+	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("migrate up: %w", err)
+	}
+
+	return nil
+}
+
+func schemaVersion(m *migrate.Migrate) (uint, error) {
+	version, dirty, err := m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("get schema version: %w", err)
+	}
+	if dirty {
+		return 0, fmt.Errorf("schema version %d is dirty", version)
+	}
+
+	return version, nil
+}
+
+// runData applies d once. When the schema already moved past d.Version
+// the step can't run against its schema anymore and is only recorded.
+func runData(ctx context.Context, pool *pgxpool.Pool, logger *slog.Logger, d Data, skip bool) error {
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin data migration %d: %w", d.Version, err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var applied bool
+	if err := tx.QueryRow(ctx, dataMigrationApplied, d.Version).Scan(&applied); err != nil {
+		return fmt.Errorf("check data migration %d: %w", d.Version, err)
+	}
+	if applied {
+		return nil
+	}
+
+	if skip {
+		logger.Warn("skipping data migration, schema is ahead", "version", d.Version, "name", d.Name)
+	} else {
+		logger.Info("running data migration", "version", d.Version, "name", d.Name)
+		if err := d.Up(ctx, tx, logger); err != nil {
+			return fmt.Errorf("data migration %d %s: %w", d.Version, d.Name, err)
+		}
+	}
+
+	if _, err := tx.Exec(ctx, dataMigrationInsert, d.Version, d.Name); err != nil {
+		return fmt.Errorf("record data migration %d: %w", d.Version, err)
+	}
+
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,85 @@
+// Package audience writes customer match files for ad platforms with phones hashed with SHA-256.
+package audience
+
+import (
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Layout is the CSV an ad platform accepts: the header and how an E.164 phone is written before hashing.
+type Layout struct {
+	Name   string
+	Header string
+	Phone  func(e164 string) string
+}
+
+var layouts = map[string]Layout{
+	// Google Ads Customer Match hashes E.164 with the leading plus.
+	"google": {Name: "google", Header: "Phone", Phone: func(e164 string) string { return e164 }},
+	// Meta custom audiences hash the digits with the country code and without the plus.
+	"meta": {Name: "meta", Header: "phone", Phone: func(e164 string) string { return strings.TrimPrefix(e164, "+") }},
+	// TikTok custom audiences hash E.164 with the leading plus.
+	"tiktok": {Name: "tiktok", Header: "phone", Phone: func(e164 string) string { return e164 }},
+}
+
+// ParseLayout returns the layout of a platform, see Layouts.
+func ParseLayout(name string) (Layout, error) {
+	l, ok := layouts[strings.ToLower(name)]
+	if !ok {
+		return Layout{}, fmt.Errorf("unknown audience layout %q, expected %s", name, strings.Join(Layouts(), ", "))
+	}
+
+	return l, nil
+}
+
+// Layouts lists the known platform names.
+func Layouts() []string {
+	names := make([]string, 0, len(layouts))
+	for name := range layouts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Hash is the lowercase hex SHA-256 ad platforms expect.
+func Hash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// WriteHashed writes the phones, normalized to E.164, hashed in the layout, skipping empty ones
+// and duplicates. It returns the number of rows written.
+func WriteHashed(w io.Writer, layout Layout, phones []string) (int, error) {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{layout.Header}); err != nil {
+		return 0, err
+	}
+
+	seen := make(map[string]struct{}, len(phones))
+	for _, phone := range phones {
+		if phone == "" {
+			continue
+		}
+
+		hash := Hash(layout.Phone(phone))
+		if _, ok := seen[hash]; ok {
+			continue
+		}
+		seen[hash] = struct{}{}
+
+		if err := cw.Write([]string{hash}); err != nil {
+			return 0, err
+		}
+	}
+
+	cw.Flush()
+
+	return len(seen), cw.Error()
+}
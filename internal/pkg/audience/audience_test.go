@@ -0,0 +1,58 @@
+package audience
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteHashed(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		layout string
+		want   string
+	}{
+		"google": {
+			layout: "google",
+			want:   "Phone\n" + Hash("+77011234567") + "\n" + Hash("+77770000000") + "\n",
+		},
+		"meta": {
+			layout: "Meta",
+			want:   "phone\n" + Hash("77011234567") + "\n" + Hash("77770000000") + "\n",
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			layout, err := ParseLayout(tt.layout)
+			require.NoError(t, err)
+
+			var buf bytes.Buffer
+			n, err := WriteHashed(&buf, layout, []string{"+77011234567", "", "+77770000000", "+77011234567"})
+			require.NoError(t, err)
+
+			assert.Equal(t, 2, n)
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
+
+func TestHash(t *testing.T) {
+	t.Parallel()
+
+	// echo -n '+77011234567' | sha256sum
+	assert.Equal(t, "c87ea5d396b7f3244ee926b250483c2eb3e5345c14eb18eaa874ed2edbf903af", Hash("+77011234567"))
+}
+
+func TestParseLayout_Unknown(t *testing.T) {
+	t.Parallel()
+
+	_, err := ParseLayout("myspace")
+	assert.ErrorContains(t, err, "expected google, meta, tiktok")
+}
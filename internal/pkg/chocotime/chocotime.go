@@ -0,0 +1,137 @@
+// Package chocotime parses timestamps returned by the Choco API and builds date filters for it.
+// The API works in the Asia/Almaty wall clock, values without an explicit offset are read in that zone.
+package chocotime
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+	_ "time/tzdata" // Asia/Almaty must resolve in minimal containers
+)
+
+const (
+	zoneName = "Asia/Almaty"
+	// zoneOffset is used when the tz database is unavailable, Kazakhstan is on UTC+5 since 2024.
+	zoneOffset = 5 * 60 * 60
+
+	filterLayout = "2006-01-02 15:04:05"
+)
+
+// Location is the zone the Choco API reports local times in.
+//
+//nolint:gochecknoglobals
+var Location = loadLocation()
+
+var ErrUnknownFormat = errors.New("unknown time format")
+
+// layouts are tried in order, zoned layouts keep their own offset.
+//
+//nolint:gochecknoglobals
+var layouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02 15:04",
+	"02.01.2006 15:04:05",
+	"02.01.2006 15:04",
+	"2006-01-02",
+	"02.01.2006",
+	"02/01/2006",
+}
+
+// dateLayouts are accepted by ParseDate, a civil date carries no zone.
+//
+//nolint:gochecknoglobals
+var dateLayouts = []string{
+	"2006-01-02",
+	"02.01.2006",
+	"02/01/2006",
+	"2006-01-02T15:04:05Z07:00",
+	"2006-01-02 15:04:05",
+}
+
+func loadLocation() *time.Location {
+	loc, err := time.LoadLocation(zoneName)
+	if err != nil {
+		return time.FixedZone(zoneName, zoneOffset)
+	}
+
+	return loc
+}
+
+// Parse parses any timestamp format returned by the API in the Almaty zone.
+func Parse(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, fmt.Errorf("%w: empty value", ErrUnknownFormat)
+	}
+
+	for _, layout := range layouts {
+		if t, err := time.ParseInLocation(layout, s, Location); err == nil {
+			return t.In(Location), nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("%w: %q", ErrUnknownFormat, s)
+}
+
+// ParseDate parses a calendar date (e.g. birthday) and returns its year, month and day.
+func ParseDate(s string) (int, time.Month, int, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, 0, 0, fmt.Errorf("%w: empty value", ErrUnknownFormat)
+	}
+
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			y, m, d := t.Date()
+			return y, m, d, nil
+		}
+	}
+
+	return 0, 0, 0, fmt.Errorf("%w: %q", ErrUnknownFormat, s)
+}
+
+// StartOfDay returns midnight of the Almaty day t falls on.
+func StartOfDay(t time.Time) time.Time {
+	y, m, d := t.In(Location).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, Location)
+}
+
+// DateRange is an inclusive range of whole Almaty days used as an API filter.
+type DateRange struct {
+	From time.Time
+	To   time.Time
+}
+
+// NewDateRange widens from and to to the start and the last second of their days.
+func NewDateRange(from, to time.Time) DateRange {
+	return DateRange{
+		From: StartOfDay(from),
+		To:   StartOfDay(to).AddDate(0, 0, 1).Add(-time.Second),
+	}
+}
+
+// LastDays returns the range covering today and the previous days before it.
+func LastDays(now time.Time, days int) DateRange {
+	return NewDateRange(now.AddDate(0, 0, -days), now)
+}
+
+// Start formats the lower bound the way the API expects it.
+func (r DateRange) Start() string {
+	return r.From.In(Location).Format(filterLayout)
+}
+
+// End formats the upper bound the way the API expects it.
+func (r DateRange) End() string {
+	return r.To.In(Location).Format(filterLayout)
+}
+
+// Query encodes the range as query parameters with the given names,
+// e.g. Query("filter[start_date]", "filter[end_date]").
+func (r DateRange) Query(startKey, endKey string) string {
+	return startKey + "=" + url.QueryEscape(r.Start()) + "&" + endKey + "=" + url.QueryEscape(r.End())
+}
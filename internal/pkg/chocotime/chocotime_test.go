@@ -0,0 +1,62 @@
+package chocotime
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	want := time.Date(2024, time.March, 5, 14, 7, 9, 0, Location)
+
+	tests := map[string]string{
+		"sql":          "2024-03-05 14:07:09",
+		"iso_local":    "2024-03-05T14:07:09",
+		"iso_utc":      "2024-03-05T09:07:09Z",
+		"iso_offset":   "2024-03-05T14:07:09+05:00",
+		"iso_fraction": "2024-03-05T09:07:09.000000Z",
+		"dotted":       "05.03.2024 14:07:09",
+	}
+
+	for name, in := range tests {
+		in := in
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := Parse(in)
+			require.NoError(t, err)
+			assert.True(t, want.Equal(got), "got %s", got)
+		})
+	}
+
+	_, err := Parse("yesterday")
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+}
+
+func TestParseDate(t *testing.T) {
+	t.Parallel()
+
+	for _, in := range []string{"1990-02-28", "28.02.1990", "1990-02-28T00:00:00+05:00"} {
+		y, m, d, err := ParseDate(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, []int{1990, 2, 28}, []int{y, int(m), d}, in)
+	}
+}
+
+func TestDateRange_Query(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, time.March, 5, 1, 0, 0, 0, time.UTC)
+	r := LastDays(now, 2)
+
+	assert.Equal(t, "2024-03-03 00:00:00", r.Start())
+	assert.Equal(t, "2024-03-05 23:59:59", r.End())
+	assert.Equal(t,
+		"filter[start_date]=2024-03-03+00%3A00%3A00&filter[end_date]=2024-03-05+23%3A59%3A59",
+		r.Query("filter[start_date]", "filter[end_date]"),
+	)
+}
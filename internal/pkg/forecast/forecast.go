@@ -0,0 +1,141 @@
+// Package forecast fits additive Holt-Winters models to daily series.
+package forecast
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+var ErrShortSeries = errors.New("series is too short")
+
+// Params are the smoothing factors of level, trend and season, each within [0, 1].
+type Params struct {
+	Alpha float64
+	Beta  float64
+	Gamma float64
+}
+
+func (p Params) String() string {
+	return fmt.Sprintf("alpha=%.2f beta=%.2f gamma=%.2f", p.Alpha, p.Beta, p.Gamma)
+}
+
+// Model is an additive Holt-Winters model fitted to a series.
+type Model struct {
+	Params Params
+	Season int
+	// Sigma is the standard deviation of the one-step-ahead errors over the series.
+	Sigma float64
+
+	level    float64
+	trend    float64
+	seasonal []float64
+	// n is the length of the fitted series, it tells the season index of the next value.
+	n int
+}
+
+// Point is a forecast value with its prediction interval.
+type Point struct {
+	Value float64
+	Lower float64
+	Upper float64
+}
+
+// Fit picks the smoothing factors with the least squared one-step-ahead error on a grid
+// and returns the model fitted with them. The series needs at least two seasons.
+func Fit(series []float64, season int) (*Model, error) {
+	if season < 2 {
+		return nil, fmt.Errorf("season must be at least 2, got %d", season)
+	}
+	if len(series) < 2*season {
+		return nil, fmt.Errorf("%w: %d values for a season of %d", ErrShortSeries, len(series), season)
+	}
+
+	var (
+		best    *Model
+		bestSSE = math.Inf(1)
+	)
+	for _, alpha := range grid {
+		for _, beta := range grid {
+			for _, gamma := range grid {
+				m, sse := fit(series, season, Params{Alpha: alpha, Beta: beta, Gamma: gamma})
+				if sse < bestSSE {
+					best, bestSSE = m, sse
+				}
+			}
+		}
+	}
+
+	return best, nil
+}
+
+var grid = []float64{0.05, 0.1, 0.2, 0.3, 0.4, 0.5, 0.6, 0.7, 0.8, 0.9}
+
+// fit runs the smoothing over the series and returns the model with its squared error sum.
+// The first season initializes the model and isn't counted in the error.
+func fit(series []float64, season int, p Params) (*Model, float64) {
+	first := mean(series[:season])
+	second := mean(series[season : 2*season])
+
+	m := &Model{
+		Params:   p,
+		Season:   season,
+		level:    first,
+		trend:    (second - first) / float64(season),
+		seasonal: make([]float64, season),
+	}
+	for i := 0; i < season; i++ {
+		m.seasonal[i] = series[i] - first
+	}
+
+	var sse float64
+	for t := season; t < len(series); t++ {
+		s := m.seasonal[t%season]
+		err := series[t] - (m.level + m.trend + s)
+		sse += err * err
+
+		level := p.Alpha*(series[t]-s) + (1-p.Alpha)*(m.level+m.trend)
+		m.trend = p.Beta*(level-m.level) + (1-p.Beta)*m.trend
+		m.seasonal[t%season] = p.Gamma*(series[t]-level) + (1-p.Gamma)*s
+		m.level = level
+	}
+
+	m.n = len(series)
+	m.Sigma = math.Sqrt(sse / float64(len(series)-season))
+
+	return m, sse
+}
+
+// Forecast predicts the next h values with prediction intervals of z standard deviations,
+// e.g. 1.96 for 95%. The intervals widen with the horizon as in Hyndman et al.,
+// "Forecasting with Exponential Smoothing", for the additive model.
+func (m *Model) Forecast(h int, z float64) []Point {
+	points := make([]Point, h)
+
+	variance := 0.0
+	for k := 1; k <= h; k++ {
+		if k > 1 {
+			j := float64(k - 1)
+			c := m.Params.Alpha * (1 + j*m.Params.Beta)
+			if (k-1)%m.Season == 0 {
+				c += m.Params.Gamma
+			}
+			variance += c * c
+		}
+
+		value := m.level + float64(k)*m.trend + m.seasonal[(m.n+k-1)%m.Season]
+		spread := z * m.Sigma * math.Sqrt(1+variance)
+		points[k-1] = Point{Value: value, Lower: value - spread, Upper: value + spread}
+	}
+
+	return points
+}
+
+func mean(values []float64) float64 {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+
+	return sum / float64(len(values))
+}
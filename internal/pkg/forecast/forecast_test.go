@@ -0,0 +1,65 @@
+package forecast
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFit_WeeklySeason(t *testing.T) {
+	t.Parallel()
+
+	week := []float64{100, 120, 110, 130, 200, 260, 180}
+	var series []float64
+	for w := 0; w < 8; w++ {
+		for _, v := range week {
+			series = append(series, v+float64(w)*7)
+		}
+	}
+
+	m, err := Fit(series, 7)
+	require.NoError(t, err)
+
+	points := m.Forecast(14, 1.96)
+	require.Len(t, points, 14)
+
+	for k, p := range points {
+		want := week[k%7] + 56 + float64(7*(k/7))
+		assert.InDelta(t, want, p.Value, 2, "day %d", k)
+		assert.LessOrEqual(t, p.Lower, p.Value)
+		assert.GreaterOrEqual(t, p.Upper, p.Value)
+	}
+	assert.GreaterOrEqual(t, points[13].Upper-points[13].Lower, points[0].Upper-points[0].Lower)
+}
+
+func TestFit_NoisyIntervalsCoverActuals(t *testing.T) {
+	t.Parallel()
+
+	noise := []float64{5, -3, 8, -6, 2, -9, 4, 7, -2, -5, 6, -4, 3, -7}
+	value := func(t int) float64 {
+		return 500 + 150*math.Sin(2*math.Pi*float64(t)/7) + noise[t%len(noise)]
+	}
+
+	var series []float64
+	for i := 0; i < 70; i++ {
+		series = append(series, value(i))
+	}
+
+	m, err := Fit(series, 7)
+	require.NoError(t, err)
+	assert.Greater(t, m.Sigma, 0.0)
+
+	for k, p := range m.Forecast(7, 1.96) {
+		actual := value(70 + k)
+		assert.True(t, p.Lower <= actual && actual <= p.Upper, "day %d: %v not within [%v, %v]", k, actual, p.Lower, p.Upper)
+	}
+}
+
+func TestFit_ShortSeries(t *testing.T) {
+	t.Parallel()
+
+	_, err := Fit(make([]float64, 13), 7)
+	assert.ErrorIs(t, err, ErrShortSeries)
+}
@@ -0,0 +1,71 @@
+// Package heatmap renders a weekday by hour grid as a self-contained SVG.
+package heatmap
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strings"
+)
+
+// Weekdays label the grid rows, Monday first.
+var Weekdays = [7]string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"}
+
+// Grid holds a value per weekday, Monday first, and hour of the day.
+type Grid [7][24]float64
+
+func (g *Grid) Max() float64 {
+	var m float64
+	for _, row := range g {
+		for _, v := range row {
+			m = max(m, v)
+		}
+	}
+
+	return m
+}
+
+const (
+	cell    = 28
+	left    = 48
+	top     = 48
+	opacity = 0.08
+)
+
+// WriteSVG draws the grid with cells shaded by their share of the maximum value.
+// Each cell carries its value formatted by format as a tooltip.
+func WriteSVG(w io.Writer, title string, g Grid, format func(float64) string) error {
+	width := left + 24*cell + 8
+	height := top + 7*cell + 8
+	peak := g.Max()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" font-family="sans-serif" font-size="11">`+"\n", width, height)
+	fmt.Fprintf(&b, `<text x="%d" y="18" font-size="14">%s</text>`+"\n", left, html.EscapeString(title))
+
+	for h := 0; h < 24; h++ {
+		fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="middle">%02d</text>`+"\n", left+h*cell+cell/2, top-6, h)
+	}
+
+	for d, row := range g {
+		y := top + d*cell
+		fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="end">%s</text>`+"\n", left-6, y+cell/2+4, Weekdays[d])
+
+		for h, v := range row {
+			shade := opacity
+			if peak > 0 {
+				shade += (1 - opacity) * v / peak
+			}
+			fmt.Fprintf(&b,
+				`<rect x="%d" y="%d" width="%d" height="%d" fill="#d7301f" fill-opacity="%.2f" stroke="#fff">`+
+					`<title>%s %02d:00 %s</title></rect>`+"\n",
+				left+h*cell, y, cell, cell, shade, Weekdays[d], h, html.EscapeString(format(v)),
+			)
+		}
+	}
+
+	b.WriteString("</svg>\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,41 @@
+package heatmap
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteSVG(t *testing.T) {
+	t.Parallel()
+
+	var g Grid
+	g[0][9] = 4
+	g[6][23] = 2
+
+	var buf bytes.Buffer
+	err := WriteSVG(&buf, "visits <Dostyk>", g, func(v float64) string { return strconv.FormatFloat(v, 'f', 0, 64) })
+	require.NoError(t, err)
+
+	svg := buf.String()
+	assert.True(t, strings.HasPrefix(svg, "<svg "))
+	assert.Contains(t, svg, "visits &lt;Dostyk&gt;")
+	assert.Equal(t, 7*24, strings.Count(svg, "<rect "))
+	assert.Contains(t, svg, `fill-opacity="1.00" stroke="#fff"><title>Mon 09:00 4</title>`)
+	assert.Contains(t, svg, `fill-opacity="0.54" stroke="#fff"><title>Sun 23:00 2</title>`)
+	assert.Contains(t, svg, `fill-opacity="0.08" stroke="#fff"><title>Tue 00:00 0</title>`)
+}
+
+func TestGrid_Max(t *testing.T) {
+	t.Parallel()
+
+	var g Grid
+	assert.Equal(t, 0.0, g.Max())
+
+	g[3][12] = 7.5
+	assert.Equal(t, 7.5, g.Max())
+}
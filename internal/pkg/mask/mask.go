@@ -0,0 +1,85 @@
+// Package mask hides personal data in exports: phones and names are masked
+// and user IDs are replaced with a keyed HMAC pseudonym that is stable across exports.
+package mask
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	minKeyLength    = 16
+	pseudonymPrefix = "u_"
+	// pseudonymBytes of the HMAC are kept, 64 bits are plenty to join exports without collisions
+	pseudonymBytes = 8
+	maskRune       = '*'
+	phoneKeepHead  = 4
+	phoneKeepTail  = 2
+)
+
+var ErrShortKey = errors.New("pseudonym key must be at least 16 bytes")
+
+// Masker masks personal data. A nil *Masker leaves values untouched,
+// so callers don't need to branch on whether masking is enabled.
+type Masker struct {
+	key []byte
+}
+
+func New(key string) (*Masker, error) {
+	if len(key) < minKeyLength {
+		return nil, ErrShortKey
+	}
+
+	return &Masker{key: []byte(key)}, nil
+}
+
+// Phone keeps the country/operator prefix and the last digits: "+77011234567" -> "+770******67".
+func (m *Masker) Phone(phone string) string {
+	if m == nil || phone == "" {
+		return phone
+	}
+
+	runes := []rune(phone)
+	if len(runes) <= phoneKeepHead+phoneKeepTail {
+		return strings.Repeat(string(maskRune), len(runes))
+	}
+
+	for i := phoneKeepHead; i < len(runes)-phoneKeepTail; i++ {
+		if runes[i] != ' ' && runes[i] != '-' {
+			runes[i] = maskRune
+		}
+	}
+
+	return string(runes)
+}
+
+// Name keeps the first letter of every word: "Aigerim Nurlanovna" -> "A****** N*********".
+func (m *Masker) Name(name string) string {
+	if m == nil || name == "" {
+		return name
+	}
+
+	words := strings.Fields(name)
+	for i, w := range words {
+		first, size := utf8.DecodeRuneInString(w)
+		words[i] = string(first) + strings.Repeat(string(maskRune), utf8.RuneCountInString(w[size:]))
+	}
+
+	return strings.Join(words, " ")
+}
+
+// UserID replaces the id with its pseudonym, the same id and key always give the same pseudonym.
+func (m *Masker) UserID(id string) string {
+	if m == nil || id == "" {
+		return id
+	}
+
+	mac := hmac.New(sha256.New, m.key)
+	mac.Write([]byte(id))
+
+	return pseudonymPrefix + hex.EncodeToString(mac.Sum(nil)[:pseudonymBytes])
+}
@@ -0,0 +1,46 @@
+package mask
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMasker(t *testing.T) {
+	t.Parallel()
+
+	m, err := New("0123456789abcdef")
+	require.NoError(t, err)
+
+	assert.Equal(t, "+770******67", m.Phone("+77011234567"))
+	assert.Equal(t, "8 (7*** ***-**-67", m.Phone("8 (701) 123-45-67"))
+	assert.Equal(t, "A****** N*********", m.Name("Aigerim Nurlanovna"))
+	assert.Equal(t, "А***", m.Name("Анна"))
+
+	pseudonym := m.UserID("12343106")
+	assert.Len(t, pseudonym, len(pseudonymPrefix)+2*pseudonymBytes)
+	assert.Equal(t, pseudonym, m.UserID("12343106"))
+	assert.NotEqual(t, pseudonym, m.UserID("12343107"))
+
+	other, err := New("fedcba9876543210")
+	require.NoError(t, err)
+	assert.NotEqual(t, pseudonym, other.UserID("12343106"))
+}
+
+func TestMasker_Nil(t *testing.T) {
+	t.Parallel()
+
+	var m *Masker
+
+	assert.Equal(t, "+77011234567", m.Phone("+77011234567"))
+	assert.Equal(t, "Aigerim", m.Name("Aigerim"))
+	assert.Equal(t, "42", m.UserID("42"))
+}
+
+func TestNew_ShortKey(t *testing.T) {
+	t.Parallel()
+
+	_, err := New("short")
+	assert.ErrorIs(t, err, ErrShortKey)
+}
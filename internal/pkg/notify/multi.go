@@ -0,0 +1,19 @@
+package notify
+
+import (
+	"context"
+
+	"go.uber.org/multierr"
+)
+
+// Multi sends every message through all of its notifiers, a failing one doesn't stop the others.
+type Multi []Notifier
+
+func (m Multi) Notify(ctx context.Context, msg Message) error {
+	var err error
+	for _, n := range m {
+		err = multierr.Append(err, n.Notify(ctx, msg))
+	}
+
+	return err
+}
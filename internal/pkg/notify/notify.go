@@ -0,0 +1,34 @@
+// Package notify delivers operational messages such as alerts and sync failures.
+package notify
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Message is a notification, Subject is a one line summary.
+type Message struct {
+	Subject string    `json:"subject"`
+	Body    string    `json:"body"`
+	SentAt  time.Time `json:"sent_at"`
+}
+
+type Notifier interface {
+	Notify(ctx context.Context, msg Message) error
+}
+
+// Writer prints messages to w, e.g. stdout, and is the notifier used when nothing else is configured.
+type Writer struct {
+	w io.Writer
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+func (n *Writer) Notify(_ context.Context, msg Message) error {
+	_, err := fmt.Fprintf(n.w, "[%s] %s\n%s\n", msg.SentAt.Format(time.RFC3339), msg.Subject, msg.Body)
+	return err
+}
@@ -0,0 +1,25 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriter_Notify(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	err := NewWriter(&buf).Notify(context.Background(), Message{
+		Subject: "revenue drop",
+		Body:    "Dostyk is 40% below average",
+		SentAt:  time.Date(2025, time.May, 2, 9, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "[2025-05-02T09:00:00Z] revenue drop\nDostyk is 40% below average\n", buf.String())
+}
@@ -0,0 +1,145 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// smtpTimeout bounds a mail when the context has no deadline.
+const smtpTimeout = 30 * time.Second
+
+// SMTP mails messages as plain text. Credentials are optional, net/smtp sends them
+// only over TLS or to localhost.
+type SMTP struct {
+	addr string
+	auth smtp.Auth
+	from string
+	to   []string
+}
+
+func NewSMTP(addr, username, password, from string, to []string) *SMTP {
+	var auth smtp.Auth
+	if username != "" {
+		host, _, _ := net.SplitHostPort(addr)
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return &SMTP{
+		addr: addr,
+		auth: auth,
+		from: from,
+		to:   to,
+	}
+}
+
+// Notify sends the mail within ctx's deadline, or within smtpTimeout when ctx has none.
+func (n *SMTP) Notify(ctx context.Context, msg Message) error {
+	if len(n.to) == 0 {
+		return fmt.Errorf("smtp notifier has no recipients")
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(smtpTimeout)
+	}
+
+	dialer := net.Dialer{Deadline: deadline}
+	conn, err := dialer.DialContext(ctx, "tcp", n.addr)
+	if err != nil {
+		return fmt.Errorf("dial smtp server: %w", err)
+	}
+	defer conn.Close()
+
+	if err := conn.SetDeadline(deadline); err != nil {
+		return fmt.Errorf("set smtp deadline: %w", err)
+	}
+	stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+	defer stop()
+
+	if err := n.send(conn, msg); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("send mail: %w", ctx.Err())
+		}
+		return fmt.Errorf("send mail: %w", err)
+	}
+
+	return nil
+}
+
+// send talks to the server the way smtp.SendMail does.
+func (n *SMTP) send(conn net.Conn, msg Message) error {
+	host, _, _ := net.SplitHostPort(n.addr)
+
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if n.auth != nil {
+		if ok, _ := c.Extension("AUTH"); ok {
+			if err := c.Auth(n.auth); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := c.Mail(n.from); err != nil {
+		return err
+	}
+	for _, to := range n.to {
+		if err := c.Rcpt(to); err != nil {
+			return err
+		}
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(n.mail(msg)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return c.Quit()
+}
+
+func (n *SMTP) mail(msg Message) []byte {
+	sentAt := msg.SentAt
+	if sentAt.IsZero() {
+		sentAt = time.Now()
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", n.from)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(n.to, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", headerValue(msg.Subject)))
+	fmt.Fprintf(&b, "Date: %s\r\n", sentAt.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(strings.ReplaceAll(msg.Body, "\r\n", "\n"), "\n", "\r\n"))
+	b.WriteString("\r\n")
+
+	return b.Bytes()
+}
+
+// headerValue keeps a value on one header line, non-ASCII values are then Q-encoded.
+func headerValue(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,126 @@
+package notify
+
+import (
+	"context"
+	"mime"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSMTP accepts a single mail without auth or TLS and returns what it got.
+func fakeSMTP(t *testing.T) (string, <-chan []string) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ln.Close() })
+
+	got := make(chan []string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		tp := textproto.NewConn(conn)
+		var lines []string
+		_ = tp.PrintfLine("220 fake ESMTP")
+		for {
+			line, err := tp.ReadLine()
+			if err != nil {
+				return
+			}
+			cmd := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+			switch cmd {
+			case "EHLO", "HELO":
+				_ = tp.PrintfLine("250 fake")
+			case "MAIL", "RCPT":
+				lines = append(lines, line)
+				_ = tp.PrintfLine("250 ok")
+			case "DATA":
+				_ = tp.PrintfLine("354 go ahead")
+				data, err := tp.ReadDotLines()
+				if err != nil {
+					return
+				}
+				lines = append(lines, data...)
+				_ = tp.PrintfLine("250 queued")
+			case "QUIT":
+				_ = tp.PrintfLine("221 bye")
+				got <- lines
+				return
+			default:
+				_ = tp.PrintfLine("502 not implemented")
+			}
+		}
+	}()
+
+	return ln.Addr().String(), got
+}
+
+func TestSMTP_Notify(t *testing.T) {
+	t.Parallel()
+
+	addr, got := fakeSMTP(t)
+
+	n := NewSMTP(addr, "", "", "parser@example.com", []string{"ops@example.com"})
+	err := n.Notify(context.Background(), Message{
+		Subject: "alert\r\nBcc: someone@example.com",
+		Body:    "line one\nline two",
+		SentAt:  time.Date(2025, time.May, 2, 9, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+
+	lines := <-got
+	assert.Contains(t, lines, "MAIL FROM:<parser@example.com>")
+	assert.Contains(t, lines, "RCPT TO:<ops@example.com>")
+	assert.Contains(t, lines, "Subject: alert Bcc: someone@example.com")
+	assert.Contains(t, lines, "Date: Fri, 02 May 2025 09:00:00 +0000")
+	assert.Contains(t, lines, "line one")
+	assert.Contains(t, lines, "line two")
+}
+
+func TestSMTP_mail_Subject(t *testing.T) {
+	t.Parallel()
+
+	n := NewSMTP("localhost:25", "", "", "parser@example.com", []string{"ops@example.com"})
+	mail := string(n.mail(Message{Subject: "выручка упала: Достык", Body: "body"}))
+
+	assert.Contains(t, mail, "Subject: =?utf-8?q?")
+
+	dec := new(mime.WordDecoder)
+	subject, _, _ := strings.Cut(strings.SplitN(mail, "Subject: ", 2)[1], "\r\n")
+	decoded, err := dec.DecodeHeader(subject)
+	require.NoError(t, err)
+	assert.Equal(t, "выручка упала: Достык", decoded)
+}
+
+func TestSMTP_Notify_Context(t *testing.T) {
+	t.Parallel()
+
+	// the server accepts the connection but never greets
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ln.Close() })
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		t.Cleanup(func() { _ = conn.Close() })
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	n := NewSMTP(ln.Addr().String(), "", "", "parser@example.com", []string{"ops@example.com"})
+	err = n.Notify(ctx, Message{Subject: "alert", Body: "body"})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
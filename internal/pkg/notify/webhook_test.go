@@ -0,0 +1,52 @@
+package notify
+
+import (
+	"context"
+	"crypto/hmac"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhook_Notify(t *testing.T) {
+	t.Parallel()
+
+	const secret = "s3cret"
+	received := make(chan Message, 1)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil || !hmac.Equal([]byte(r.Header.Get(SignatureHeader)), []byte(Sign([]byte(secret), body))) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var msg Message
+		if err := json.Unmarshal(body, &msg); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		received <- msg
+	}))
+	defer srv.Close()
+
+	msg := Message{Subject: "sync failed", Body: "timeout", SentAt: time.Date(2025, time.May, 2, 9, 0, 0, 0, time.UTC)}
+	require.NoError(t, NewWebhook(srv.URL, secret).Notify(context.Background(), msg))
+	assert.Equal(t, msg, <-received)
+
+	err := NewWebhook(srv.URL, "wrong").Notify(context.Background(), msg)
+	assert.ErrorContains(t, err, "webhook responded 401")
+}
+
+func TestSign(t *testing.T) {
+	t.Parallel()
+
+	// echo -n '{}' | openssl dgst -sha256 -hmac key
+	assert.Equal(t, "sha256=a777724d943eb48dc69bca8a4a6d57a04db3f9ec7e1de4e581e860265bdf3032", Sign([]byte("key"), []byte("{}")))
+}
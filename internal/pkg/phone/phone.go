@@ -0,0 +1,50 @@
+// Package phone normalizes Kazakhstan phone numbers to E.164.
+package phone
+
+import (
+	"strings"
+)
+
+const (
+	countryCode    = "7"
+	nationalDigits = 10
+)
+
+// Normalize converts a Kazakhstan number written in any common format
+// ("8 (701) 123-45-67", "+7 701 123 45 67", "7011234567") to E.164, e.g. "+77011234567".
+// It reports false for masked, foreign or otherwise malformed numbers.
+func Normalize(raw string) (string, bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", false
+	}
+
+	digits := make([]byte, 0, len(raw))
+	for i, r := range raw {
+		switch {
+		case r >= '0' && r <= '9':
+			digits = append(digits, byte(r))
+		case r == '+' && i == 0:
+		case r == ' ' || r == '-' || r == '(' || r == ')' || r == '.':
+		default:
+			// masked ("+7 701 *** ** 67") or letters
+			return "", false
+		}
+	}
+
+	national := string(digits)
+	switch {
+	case len(national) == nationalDigits+1 && (national[0] == '7' || national[0] == '8'):
+		national = national[1:]
+	case len(national) == nationalDigits:
+	default:
+		return "", false
+	}
+
+	// Kazakhstan shares +7 with Russia and owns the 6xx and 7xx ranges
+	if national[0] != '6' && national[0] != '7' {
+		return "", false
+	}
+
+	return "+" + countryCode + national, true
+}
@@ -0,0 +1,43 @@
+package phone
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		in     string
+		want   string
+		wantOk bool
+	}{
+		"e164":          {in: "+77011234567", want: "+77011234567", wantOk: true},
+		"spaced":        {in: "+7 701 123 45 67", want: "+77011234567", wantOk: true},
+		"eight_prefix":  {in: "8 (701) 123-45-67", want: "+77011234567", wantOk: true},
+		"seven_prefix":  {in: "77011234567", want: "+77011234567", wantOk: true},
+		"national":      {in: "7011234567", want: "+77011234567", wantOk: true},
+		"landline":      {in: "8 727 250 00 00", want: "+77272500000", wantOk: true},
+		"russian":       {in: "+7 916 123 45 67", wantOk: false},
+		"masked":        {in: "+7 701 *** ** 67", wantOk: false},
+		"too_short":     {in: "701123", wantOk: false},
+		"foreign":       {in: "+998 90 123 45 67", wantOk: false},
+		"plus_in_body":  {in: "7701+1234567", wantOk: false},
+		"empty":         {in: "  ", wantOk: false},
+		"letters":       {in: "call me", wantOk: false},
+		"nine_prefixed": {in: "97011234567", wantOk: false},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, ok := Normalize(tt.in)
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,251 @@
+// Package report renders tabular reports and exports as a terminal table, CSV or JSON.
+// Columns holding personal data are passed through a mask.Masker on the way out.
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ibookerke/choco_parser_go/internal/pkg/chocotime"
+	"github.com/ibookerke/choco_parser_go/internal/pkg/mask"
+)
+
+// Kind tells which masking applies to a column.
+type Kind int
+
+const (
+	Plain Kind = iota
+	Phone
+	Name
+	UserID
+	// UserIDList is a comma separated list of user IDs.
+	UserIDList
+)
+
+type Format string
+
+const (
+	FormatTable Format = "table"
+	FormatCSV   Format = "csv"
+	FormatJSON  Format = "json"
+)
+
+const timeLayout = "2006-01-02 15:04"
+
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case FormatTable, FormatCSV, FormatJSON:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unknown format %q, expected table, csv or json", s)
+	}
+}
+
+// Column is named in snake_case, the name is the CSV header and the JSON key.
+type Column struct {
+	Name string
+	Kind Kind
+}
+
+// Table is a titled set of rows, every row has a value per column.
+type Table struct {
+	Title   string
+	Columns []Column
+	Rows    [][]any
+}
+
+func NewTable(title string, columns ...Column) *Table {
+	return &Table{Title: title, Columns: columns}
+}
+
+func (t *Table) Append(values ...any) {
+	t.Rows = append(t.Rows, values)
+}
+
+// Write renders the tables in the format. A nil masker writes personal data as is.
+func Write(w io.Writer, format Format, masker *mask.Masker, tables ...*Table) error {
+	switch format {
+	case FormatCSV:
+		return writeCSV(w, masker, tables)
+	case FormatJSON:
+		return writeJSON(w, masker, tables)
+	default:
+		return writeTable(w, masker, tables)
+	}
+}
+
+func writeTable(w io.Writer, masker *mask.Masker, tables []*Table) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	for i, t := range tables {
+		if i > 0 {
+			fmt.Fprintln(tw)
+		}
+		if t.Title != "" {
+			fmt.Fprintln(tw, t.Title)
+		}
+
+		headers := make([]string, len(t.Columns))
+		for j, c := range t.Columns {
+			headers[j] = strings.ToUpper(strings.ReplaceAll(c.Name, "_", " "))
+		}
+		fmt.Fprintln(tw, strings.Join(headers, "\t"))
+
+		for _, row := range t.Rows {
+			fmt.Fprintln(tw, strings.Join(t.cells(row, masker), "\t"))
+		}
+	}
+
+	return tw.Flush()
+}
+
+// writeCSV writes the tables one after another separated by an empty line,
+// exports meant for other tools should contain a single table.
+func writeCSV(w io.Writer, masker *mask.Masker, tables []*Table) error {
+	cw := csv.NewWriter(w)
+
+	for i, t := range tables {
+		if i > 0 {
+			if err := cw.Write(nil); err != nil {
+				return err
+			}
+		}
+
+		headers := make([]string, len(t.Columns))
+		for j, c := range t.Columns {
+			headers[j] = c.Name
+		}
+		if err := cw.Write(headers); err != nil {
+			return err
+		}
+
+		for _, row := range t.Rows {
+			if err := cw.Write(t.cells(row, masker)); err != nil {
+				return err
+			}
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeJSON writes a single table as an array of objects and several tables as an object keyed by title.
+func writeJSON(w io.Writer, masker *mask.Masker, tables []*Table) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	if len(tables) == 1 {
+		return enc.Encode(tables[0].objects(masker))
+	}
+
+	out := make(map[string][]map[string]any, len(tables))
+	for _, t := range tables {
+		out[t.Title] = t.objects(masker)
+	}
+
+	return enc.Encode(out)
+}
+
+func (t *Table) objects(masker *mask.Masker) []map[string]any {
+	objects := make([]map[string]any, 0, len(t.Rows))
+	for _, row := range t.Rows {
+		obj := make(map[string]any, len(t.Columns))
+		for i, c := range t.Columns {
+			if i >= len(row) {
+				break
+			}
+			if c.Kind == Plain {
+				obj[c.Name] = jsonValue(row[i])
+			} else {
+				obj[c.Name] = maskValue(c.Kind, formatValue(row[i]), masker)
+			}
+		}
+		objects = append(objects, obj)
+	}
+
+	return objects
+}
+
+func (t *Table) cells(row []any, masker *mask.Masker) []string {
+	cells := make([]string, len(t.Columns))
+	for i, c := range t.Columns {
+		if i >= len(row) {
+			break
+		}
+		cells[i] = maskValue(c.Kind, formatValue(row[i]), masker)
+	}
+
+	return cells
+}
+
+func maskValue(kind Kind, value string, masker *mask.Masker) string {
+	switch kind {
+	case Phone:
+		return masker.Phone(value)
+	case Name:
+		return masker.Name(value)
+	case UserID:
+		return masker.UserID(value)
+	case UserIDList:
+		ids := strings.Split(value, ",")
+		for i, id := range ids {
+			ids[i] = masker.UserID(strings.TrimSpace(id))
+		}
+		return strings.Join(ids, ",")
+	default:
+		return value
+	}
+}
+
+func formatValue(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case time.Time:
+		if val.IsZero() {
+			return ""
+		}
+		return val.In(chocotime.Location).Format(timeLayout)
+	case float64:
+		return strconv.FormatFloat(val, 'f', 2, 64)
+	case []int64:
+		ids := make([]string, len(val))
+		for i, id := range val {
+			ids[i] = strconv.FormatInt(id, 10)
+		}
+		return strings.Join(ids, ",")
+	case []string:
+		return strings.Join(val, ",")
+	case fmt.Stringer:
+		return val.String()
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+func jsonValue(v any) any {
+	switch val := v.(type) {
+	case nil:
+		return nil
+	case time.Time:
+		if val.IsZero() {
+			return nil
+		}
+		return val.In(chocotime.Location).Format(time.RFC3339)
+	case float64:
+		return json.Number(strconv.FormatFloat(val, 'f', 2, 64))
+	case json.Marshaler, int, int64, bool, []int64, []string:
+		return val
+	default:
+		return formatValue(val)
+	}
+}
@@ -0,0 +1,78 @@
+package report
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ibookerke/choco_parser_go/internal/pkg/mask"
+)
+
+func testTable() *Table {
+	t := NewTable("customers",
+		Column{Name: "user_id", Kind: UserID},
+		Column{Name: "full_name", Kind: Name},
+		Column{Name: "phone", Kind: Phone},
+		Column{Name: "visits"},
+	)
+	t.Append(int64(42), "Aigerim", "+77011234567", 3)
+
+	return t
+}
+
+func TestWrite_CSV(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, FormatCSV, nil, testTable()))
+
+	assert.Equal(t, "user_id,full_name,phone,visits\n42,Aigerim,+77011234567,3\n", buf.String())
+}
+
+func TestWrite_Masked(t *testing.T) {
+	t.Parallel()
+
+	masker, err := mask.New("0123456789abcdef")
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, FormatJSON, masker, testTable()))
+
+	assert.JSONEq(t, `[{
+		"user_id": "`+masker.UserID("42")+`",
+		"full_name": "A******",
+		"phone": "+770******67",
+		"visits": 3
+	}]`, buf.String())
+}
+
+func TestWrite_Lists(t *testing.T) {
+	t.Parallel()
+
+	table := NewTable("",
+		Column{Name: "user_ids", Kind: UserIDList},
+		Column{Name: "sources"},
+	)
+	table.Append([]int64{1, 2}, []string{"customers", "company_customers"})
+
+	var csv bytes.Buffer
+	require.NoError(t, Write(&csv, FormatCSV, nil, table))
+	assert.Equal(t, "user_ids,sources\n\"1,2\",\"customers,company_customers\"\n", csv.String())
+
+	var js bytes.Buffer
+	require.NoError(t, Write(&js, FormatJSON, nil, table))
+	assert.JSONEq(t, `[{"user_ids": "1,2", "sources": ["customers", "company_customers"]}]`, js.String())
+}
+
+func TestParseFormat(t *testing.T) {
+	t.Parallel()
+
+	f, err := ParseFormat("CSV")
+	require.NoError(t, err)
+	assert.Equal(t, FormatCSV, f)
+
+	_, err = ParseFormat("xml")
+	assert.Error(t, err)
+}
@@ -0,0 +1,104 @@
+// Package rfm scores customers by recency, frequency and monetary quintiles.
+package rfm
+
+import (
+	"sort"
+)
+
+const quintiles = 5
+
+// Input are the raw figures of one customer. Lower RecencyDays is better.
+type Input struct {
+	RecencyDays int
+	Frequency   int64
+	Monetary    int64
+}
+
+// Score holds quintile scores from 1 (worst) to 5 (best).
+type Score struct {
+	R int
+	F int
+	M int
+}
+
+// Segment names, see SegmentOf.
+const (
+	Champions         = "champions"
+	Loyal             = "loyal"
+	PotentialLoyalist = "potential_loyalist"
+	NewCustomers      = "new_customers"
+	NeedAttention     = "need_attention"
+	AboutToSleep      = "about_to_sleep"
+	AtRisk            = "at_risk"
+	CannotLose        = "cannot_lose"
+	Hibernating       = "hibernating"
+	Lost              = "lost"
+)
+
+// ScoreAll scores every input against the others. Equal values always get the same score.
+func ScoreAll(inputs []Input) []Score {
+	n := len(inputs)
+	scores := make([]Score, n)
+	if n == 0 {
+		return scores
+	}
+
+	// recency is inverted so that the most recent customers get 5
+	r := quintileScores(n, func(i int) int64 { return -int64(inputs[i].RecencyDays) })
+	f := quintileScores(n, func(i int) int64 { return inputs[i].Frequency })
+	m := quintileScores(n, func(i int) int64 { return inputs[i].Monetary })
+
+	for i := range scores {
+		scores[i] = Score{R: r[i], F: f[i], M: m[i]}
+	}
+
+	return scores
+}
+
+// quintileScores ranks values ascending and maps the rank of the first equal value to 1..5.
+func quintileScores(n int, value func(i int) int64) []int {
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return value(order[a]) < value(order[b]) })
+
+	scores := make([]int, n)
+	rank := 0
+	for pos, idx := range order {
+		if pos == 0 || value(idx) != value(order[pos-1]) {
+			rank = pos
+		}
+		scores[idx] = rank*quintiles/n + 1
+	}
+
+	return scores
+}
+
+// SegmentOf maps scores to a named segment using recency and the average of frequency and monetary.
+func SegmentOf(s Score) string {
+	fm := (s.F + s.M + 1) / 2
+
+	switch {
+	case s.R >= 4 && fm >= 4:
+		return Champions
+	case s.R == 3 && fm >= 4:
+		return Loyal
+	case s.R >= 4 && fm >= 2:
+		return PotentialLoyalist
+	case s.R >= 4:
+		return NewCustomers
+	case s.R == 3 && fm == 3:
+		return NeedAttention
+	case s.R == 3:
+		return AboutToSleep
+	case s.R == 2 && fm >= 4:
+		return AtRisk
+	case s.R == 1 && fm >= 4:
+		return CannotLose
+	case fm >= 2:
+		return Hibernating
+	default:
+		return Lost
+	}
+}
@@ -0,0 +1,49 @@
+package rfm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScoreAll(t *testing.T) {
+	t.Parallel()
+
+	inputs := []Input{
+		{RecencyDays: 1, Frequency: 10, Monetary: 100000},
+		{RecencyDays: 5, Frequency: 5, Monetary: 50000},
+		{RecencyDays: 30, Frequency: 3, Monetary: 30000},
+		{RecencyDays: 90, Frequency: 2, Monetary: 20000},
+		{RecencyDays: 365, Frequency: 1, Monetary: 10000},
+	}
+
+	scores := ScoreAll(inputs)
+
+	assert.Equal(t, Score{R: 5, F: 5, M: 5}, scores[0])
+	assert.Equal(t, Score{R: 3, F: 3, M: 3}, scores[2])
+	assert.Equal(t, Score{R: 1, F: 1, M: 1}, scores[4])
+}
+
+func TestScoreAll_Ties(t *testing.T) {
+	t.Parallel()
+
+	scores := ScoreAll([]Input{
+		{RecencyDays: 10, Frequency: 1, Monetary: 100},
+		{RecencyDays: 10, Frequency: 1, Monetary: 100},
+		{RecencyDays: 10, Frequency: 1, Monetary: 100},
+	})
+
+	assert.Equal(t, scores[0], scores[1])
+	assert.Equal(t, scores[1], scores[2])
+	assert.Empty(t, ScoreAll(nil))
+}
+
+func TestSegmentOf(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, Champions, SegmentOf(Score{R: 5, F: 5, M: 4}))
+	assert.Equal(t, NewCustomers, SegmentOf(Score{R: 5, F: 1, M: 1}))
+	assert.Equal(t, AtRisk, SegmentOf(Score{R: 2, F: 5, M: 5}))
+	assert.Equal(t, CannotLose, SegmentOf(Score{R: 1, F: 4, M: 5}))
+	assert.Equal(t, Lost, SegmentOf(Score{R: 1, F: 1, M: 1}))
+}
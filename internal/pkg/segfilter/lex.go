@@ -0,0 +1,121 @@
+package segfilter
+
+import (
+	"fmt"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokNumber
+	tokDuration
+	tokString
+	tokOp
+	tokLParen
+	tokRParen
+)
+
+// token is a lexeme starting at byte pos, a string's text is its unquoted value.
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func lex(src string) ([]token, error) {
+	var tokens []token
+
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(':
+			tokens = append(tokens, token{kind: tokLParen, text: "(", pos: i})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{kind: tokRParen, text: ")", pos: i})
+			i++
+		case strings.IndexByte("=!<>", c) >= 0:
+			op := src[i : i+1]
+			if i+1 < len(src) && src[i+1] == '=' {
+				op = src[i : i+2]
+			}
+			switch op {
+			case "=", "!=", "<", "<=", ">", ">=":
+			default:
+				return nil, fmt.Errorf("at %d: unknown operator %s", i, op)
+			}
+			tokens = append(tokens, token{kind: tokOp, text: op, pos: i})
+			i += len(op)
+		case c == '"':
+			t, n, err := lexString(src, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, t)
+			i += n
+		case isDigit(c) || c == '-' || c == '.':
+			j := i + 1
+			for j < len(src) && (isDigit(src[j]) || src[j] == '.') {
+				j++
+			}
+			kind := tokNumber
+			if j < len(src) && ageUnits[src[j]] != 0 {
+				kind = tokDuration
+				j++
+			}
+			if j < len(src) && isIdent(src[j]) {
+				return nil, fmt.Errorf("at %d: invalid literal %s", i, src[i:j+1])
+			}
+			tokens = append(tokens, token{kind: kind, text: src[i:j], pos: i})
+			i = j
+		case isIdent(c):
+			j := i + 1
+			for j < len(src) && (isIdent(src[j]) || isDigit(src[j])) {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokIdent, text: src[i:j], pos: i})
+			i = j
+		default:
+			return nil, fmt.Errorf("at %d: unexpected character %q", i, c)
+		}
+	}
+
+	tokens = append(tokens, token{kind: tokEOF, text: "end of filter", pos: len(src)})
+
+	return tokens, nil
+}
+
+// lexString reads a double quoted string where \" and \\ escape a quote and a backslash.
+func lexString(src string, start int) (token, int, error) {
+	var b strings.Builder
+	for i := start + 1; i < len(src); i++ {
+		switch src[i] {
+		case '\\':
+			if i+1 < len(src) && (src[i+1] == '"' || src[i+1] == '\\') {
+				b.WriteByte(src[i+1])
+				i++
+				continue
+			}
+			return token{}, 0, fmt.Errorf("at %d: invalid escape in string", i)
+		case '"':
+			return token{kind: tokString, text: b.String(), pos: start}, i + 1 - start, nil
+		default:
+			b.WriteByte(src[i])
+		}
+	}
+
+	return token{}, 0, fmt.Errorf("at %d: unterminated string", start)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdent(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
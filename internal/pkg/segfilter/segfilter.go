@@ -0,0 +1,315 @@
+// Package segfilter parses segment filters into a parameterized SQL condition, e.g.
+//
+//	turnover > 50000 AND last_visit < 30d AND company = "malatang"
+//
+// A condition compares a field with a literal, conditions combine with AND, OR, NOT and
+// parentheses. Fields map to fixed columns and literals are always bound as arguments,
+// so a filter cannot inject SQL.
+package segfilter
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Type is the kind of literal a field is compared with.
+type Type int
+
+const (
+	Number Type = iota
+	String
+	// Time fields are compared with an age such as 30d, last_visit < 30d is a visit within 30 days.
+	Time
+)
+
+// Field is a column of the customer_company_stats view aliased s or the customer_profiles view aliased p.
+type Field struct {
+	Column string
+	Type   Type
+}
+
+// Fields are the names a filter may use.
+var Fields = map[string]Field{
+	"company":      {Column: "s.company", Type: String},
+	"turnover":     {Column: "s.turnover", Type: Number},
+	"visits":       {Column: "s.visits_count", Type: Number},
+	"average_bill": {Column: "s.average_bill", Type: Number},
+	"last_visit":   {Column: "s.last_visit_date", Type: Time},
+	"name":         {Column: "p.full_name", Type: String},
+	"orders":       {Column: "p.orders_count", Type: Number},
+}
+
+const (
+	maxLength = 1000
+	maxDepth  = 32
+)
+
+// Filter is a parsed and type checked filter.
+type Filter struct {
+	src  string
+	root node
+}
+
+// Parse checks the filter's syntax and that every field is compared with a literal of its type.
+func Parse(src string) (*Filter, error) {
+	if len(src) > maxLength {
+		return nil, fmt.Errorf("filter is longer than %d characters", maxLength)
+	}
+
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	root, err := p.or(0)
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, fmt.Errorf("at %d: unexpected %s", t.pos, t.text)
+	}
+
+	return &Filter{src: src, root: root}, nil
+}
+
+func (f *Filter) String() string {
+	return f.src
+}
+
+// SQL is the condition with $1, $2... placeholders and their arguments,
+// ages are turned into timestamps relative to now.
+func (f *Filter) SQL(now time.Time) (string, []any) {
+	b := &builder{now: now}
+	f.root.sql(b)
+
+	return b.String(), b.args
+}
+
+type node interface {
+	sql(b *builder)
+}
+
+type logical struct {
+	op          string
+	left, right node
+}
+
+type not struct {
+	x node
+}
+
+type condition struct {
+	field Field
+	op    string
+	value any
+}
+
+type builder struct {
+	strings.Builder
+	now  time.Time
+	args []any
+}
+
+func (b *builder) arg(v any) string {
+	b.args = append(b.args, v)
+	return "$" + strconv.Itoa(len(b.args))
+}
+
+func (n *logical) sql(b *builder) {
+	b.WriteString("(")
+	n.left.sql(b)
+	b.WriteString(" " + n.op + " ")
+	n.right.sql(b)
+	b.WriteString(")")
+}
+
+func (n *not) sql(b *builder) {
+	b.WriteString("NOT ")
+	n.x.sql(b)
+}
+
+// ageOps flip the comparison of an age into one of a timestamp: younger than 30d is after now-30d.
+var ageOps = map[string]string{"<": ">", "<=": ">=", ">": "<", ">=": "<="}
+
+func (n *condition) sql(b *builder) {
+	switch n.field.Type {
+	case Number:
+		fmt.Fprintf(b, "%s %s %s::NUMERIC", n.field.Column, n.op, b.arg(n.value))
+	case String:
+		op := n.op
+		if op == "!=" {
+			op = "<>"
+		}
+		fmt.Fprintf(b, "lower(%s) %s lower(%s::TEXT)", n.field.Column, op, b.arg(n.value))
+	case Time:
+		at := b.now.Add(-n.value.(time.Duration))
+		fmt.Fprintf(b, "%s %s %s::TIMESTAMPTZ", n.field.Column, ageOps[n.op], b.arg(at))
+	}
+}
+
+type parser struct {
+	tokens []token
+	i      int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.i]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.i]
+	if t.kind != tokEOF {
+		p.i++
+	}
+
+	return t
+}
+
+func (p *parser) keyword(word string) bool {
+	t := p.peek()
+	if t.kind == tokIdent && strings.EqualFold(t.text, word) {
+		p.i++
+		return true
+	}
+
+	return false
+}
+
+func (p *parser) or(depth int) (node, error) {
+	left, err := p.and(depth)
+	if err != nil {
+		return nil, err
+	}
+
+	for p.keyword("OR") {
+		right, err := p.and(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = &logical{op: "OR", left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) and(depth int) (node, error) {
+	left, err := p.unary(depth)
+	if err != nil {
+		return nil, err
+	}
+
+	for p.keyword("AND") {
+		right, err := p.unary(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = &logical{op: "AND", left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) unary(depth int) (node, error) {
+	if depth > maxDepth {
+		return nil, fmt.Errorf("at %d: filter is nested deeper than %d", p.peek().pos, maxDepth)
+	}
+
+	if p.keyword("NOT") {
+		x, err := p.unary(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		return &not{x: x}, nil
+	}
+
+	if p.peek().kind == tokLParen {
+		p.next()
+		x, err := p.or(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		if t := p.next(); t.kind != tokRParen {
+			return nil, fmt.Errorf("at %d: expected ), got %s", t.pos, t.text)
+		}
+		return x, nil
+	}
+
+	return p.condition()
+}
+
+func (p *parser) condition() (node, error) {
+	name := p.next()
+	if name.kind != tokIdent {
+		return nil, fmt.Errorf("at %d: expected a field, got %s", name.pos, name.text)
+	}
+	field, ok := Fields[strings.ToLower(name.text)]
+	if !ok {
+		return nil, fmt.Errorf("at %d: unknown field %s, expected one of %s", name.pos, name.text, fieldNames())
+	}
+
+	op := p.next()
+	if op.kind != tokOp {
+		return nil, fmt.Errorf("at %d: expected a comparison after %s, got %s", op.pos, name.text, op.text)
+	}
+
+	lit := p.next()
+	switch {
+	case field.Type == Number && lit.kind == tokNumber:
+		value, err := strconv.ParseFloat(lit.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("at %d: invalid number %s", lit.pos, lit.text)
+		}
+		return &condition{field: field, op: op.text, value: value}, nil
+	case field.Type == String && lit.kind == tokString:
+		if op.text != "=" && op.text != "!=" {
+			return nil, fmt.Errorf("at %d: %s can only be compared with = or !=", op.pos, name.text)
+		}
+		return &condition{field: field, op: op.text, value: lit.text}, nil
+	case field.Type == Time && lit.kind == tokDuration:
+		if _, ok := ageOps[op.text]; !ok {
+			return nil, fmt.Errorf("at %d: %s can only be compared with <, <=, > or >=", op.pos, name.text)
+		}
+		value, err := parseAge(lit.text)
+		if err != nil {
+			return nil, fmt.Errorf("at %d: %w", lit.pos, err)
+		}
+		return &condition{field: field, op: op.text, value: value}, nil
+	default:
+		return nil, fmt.Errorf("at %d: %s is compared with %s, got %s", lit.pos, name.text, typeNames[field.Type], lit.text)
+	}
+}
+
+var typeNames = map[Type]string{
+	Number: "a number",
+	String: `a "quoted string"`,
+	Time:   "an age such as 30d or 4w",
+}
+
+var ageUnits = map[byte]time.Duration{
+	'h': time.Hour,
+	'd': 24 * time.Hour,
+	'w': 7 * 24 * time.Hour,
+}
+
+func parseAge(s string) (time.Duration, error) {
+	n, err := strconv.Atoi(s[:len(s)-1])
+	if err != nil || n > 100*365 {
+		return 0, fmt.Errorf("invalid age %s", s)
+	}
+
+	return time.Duration(n) * ageUnits[s[len(s)-1]], nil
+}
+
+func fieldNames() string {
+	names := make([]string, 0, len(Fields))
+	for name := range Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return strings.Join(names, ", ")
+}
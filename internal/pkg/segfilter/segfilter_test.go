@@ -0,0 +1,119 @@
+package segfilter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilter_SQL(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 6, 30, 12, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		src      string
+		wantSQL  string
+		wantArgs []any
+	}{
+		"conjunction": {
+			src:      `turnover > 50000 AND last_visit < 30d AND company = "malatang"`,
+			wantSQL:  "((s.turnover > $1::NUMERIC AND s.last_visit_date > $2::TIMESTAMPTZ) AND lower(s.company) = lower($3::TEXT))",
+			wantArgs: []any{50000.0, now.AddDate(0, 0, -30), "malatang"},
+		},
+		"precedence": {
+			src:      `visits >= 3 or orders < 2 and not name != "Aigerim"`,
+			wantSQL:  "(s.visits_count >= $1::NUMERIC OR (p.orders_count < $2::NUMERIC AND NOT lower(p.full_name) <> lower($3::TEXT)))",
+			wantArgs: []any{3.0, 2.0, "Aigerim"},
+		},
+		"parentheses": {
+			src:      `(average_bill <= 2500.5 OR last_visit >= 4w) AND company = "say \"cheese\""`,
+			wantSQL:  "((s.average_bill <= $1::NUMERIC OR s.last_visit_date <= $2::TIMESTAMPTZ) AND lower(s.company) = lower($3::TEXT))",
+			wantArgs: []any{2500.5, now.AddDate(0, 0, -28), `say "cheese"`},
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			f, err := Parse(tt.src)
+			require.NoError(t, err)
+
+			sql, args := f.SQL(now)
+			assert.Equal(t, tt.wantSQL, sql)
+			assert.Equal(t, tt.wantArgs, args)
+		})
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		src     string
+		wantErr string
+	}{
+		"injection": {
+			src:     `company = "x"; DROP TABLE customers`,
+			wantErr: "at 13: unexpected character ';'",
+		},
+		"unknown field": {
+			src:     `phone = "+77011234567"`,
+			wantErr: "at 0: unknown field phone",
+		},
+		"wrong literal": {
+			src:     `turnover > "big"`,
+			wantErr: "at 11: turnover is compared with a number",
+		},
+		"string order": {
+			src:     `company > "a"`,
+			wantErr: "at 8: company can only be compared with = or !=",
+		},
+		"age equality": {
+			src:     `last_visit = 30d`,
+			wantErr: "at 11: last_visit can only be compared with <, <=, > or >=",
+		},
+		"missing paren": {
+			src:     `(visits > 1`,
+			wantErr: "at 11: expected ), got end of filter",
+		},
+		"trailing": {
+			src:     `visits > 1 visits`,
+			wantErr: "at 11: unexpected visits",
+		},
+		"unterminated": {
+			src:     `company = "malatang`,
+			wantErr: "at 10: unterminated string",
+		},
+		"bad literal": {
+			src:     `visits > 10x`,
+			wantErr: "at 9: invalid literal 10x",
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := Parse(tt.src)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestParse_Depth(t *testing.T) {
+	t.Parallel()
+
+	src := ""
+	for range maxDepth + 2 {
+		src += "NOT "
+	}
+
+	_, err := Parse(src + "visits > 1")
+	assert.ErrorContains(t, err, "nested deeper than")
+}
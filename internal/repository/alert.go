@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ibookerke/choco_parser_go/internal/domain"
+	trmpgx "github.com/ibookerke/choco_parser_go/internal/pkg/pgx"
+	"github.com/ibookerke/choco_parser_go/internal/pkg/trm"
+)
+
+type AlertRepository struct {
+	pool   *pgxpool.Pool
+	getter *trmpgx.CtxGetter
+	trm    trm.Manager
+}
+
+func NewAlertRepository(
+	pool *pgxpool.Pool,
+	getter *trmpgx.CtxGetter,
+	trm trm.Manager,
+) *AlertRepository {
+	return &AlertRepository{
+		pool:   pool,
+		getter: getter,
+		trm:    trm,
+	}
+}
+
+const (
+	// alertPaymentTimesSQL lists the payments of every location since $2
+	// together with the latest payment before it.
+	alertPaymentTimesSQL = `SELECT location_title, created_at
+	FROM payments
+	WHERE type = 'pay'
+		AND location_title IS NOT NULL
+		AND ($1 = '' OR location_title ILIKE '%' || $1 || '%')
+		AND created_at >= $2
+	UNION ALL
+	SELECT location_title, MAX(created_at)
+	FROM payments
+	WHERE type = 'pay'
+		AND location_title IS NOT NULL
+		AND ($1 = '' OR location_title ILIKE '%' || $1 || '%')
+		AND created_at < $2
+	GROUP BY location_title
+	ORDER BY 1, 2`
+
+	alertEvaluatedAtSQL = `SELECT MAX(evaluated_at) FROM alert_evaluations WHERE rule = $1`
+
+	alertSetEvaluatedAtSQL = `INSERT INTO alert_evaluations (rule, evaluated_at) VALUES ($1, $2)
+	ON CONFLICT (rule) DO UPDATE SET evaluated_at = EXCLUDED.evaluated_at`
+
+	alertStoreSQL = `INSERT INTO alerts (rule, kind, location, key, message, triggered_at)
+	VALUES ($1, $2, $3, $4, $5, $6)
+	ON CONFLICT (rule, location, key) DO NOTHING
+	RETURNING id`
+
+	alertUnnotifiedSQL = `SELECT id, rule, kind, location, key, message, triggered_at
+	FROM alerts
+	WHERE rule = $1 AND notified_at IS NULL
+	ORDER BY triggered_at, location, id`
+
+	alertMarkNotifiedSQL = `UPDATE alerts SET notified_at = $2 WHERE id = $1`
+)
+
+func (r *AlertRepository) PaymentTimes(ctx context.Context, location string, since time.Time) ([]domain.LocationPayments, error) {
+	exec := r.getter.DefaultTrOrDB(ctx, r.pool)
+
+	rows, err := exec.Query(ctx, alertPaymentTimesSQL, location, since)
+	if err != nil {
+		return nil, fmt.Errorf("get payment times: %w", wrapScanError(err))
+	}
+	defer rows.Close()
+
+	var payments []domain.LocationPayments
+	for rows.Next() {
+		var (
+			title string
+			at    time.Time
+		)
+		if err := rows.Scan(&title, &at); err != nil {
+			return nil, fmt.Errorf("scan payment times: %w", wrapScanError(err))
+		}
+
+		if n := len(payments); n == 0 || payments[n-1].Location != title {
+			payments = append(payments, domain.LocationPayments{Location: title})
+		}
+		payments[len(payments)-1].Times = append(payments[len(payments)-1].Times, at)
+	}
+
+	return payments, rows.Err()
+}
+
+func (r *AlertRepository) EvaluatedAt(ctx context.Context, rule string) (time.Time, error) {
+	exec := r.getter.DefaultTrOrDB(ctx, r.pool)
+
+	var evaluatedAt pgtype.Timestamptz
+	if err := exec.QueryRow(ctx, alertEvaluatedAtSQL, rule).Scan(&evaluatedAt); err != nil {
+		return time.Time{}, fmt.Errorf("get alert evaluation: %w", wrapScanError(err))
+	}
+
+	return evaluatedAt.Time, nil
+}
+
+func (r *AlertRepository) SetEvaluatedAt(ctx context.Context, rule string, at time.Time) error {
+	exec := r.getter.DefaultTrOrDB(ctx, r.pool)
+
+	if _, err := exec.Exec(ctx, alertSetEvaluatedAtSQL, rule, at); err != nil {
+		return fmt.Errorf("set alert evaluation: %w", wrapScanError(err))
+	}
+
+	return nil
+}
+
+func (r *AlertRepository) Store(ctx context.Context, alert *domain.Alert) (bool, error) {
+	exec := r.getter.DefaultTrOrDB(ctx, r.pool)
+
+	err := exec.QueryRow(ctx, alertStoreSQL,
+		alert.Rule,
+		alert.Kind,
+		alert.Location,
+		alert.Key,
+		alert.Message,
+		alert.TriggeredAt,
+	).Scan(&alert.ID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("store alert: %w", wrapScanError(err))
+	}
+
+	return true, nil
+}
+
+func (r *AlertRepository) Unnotified(ctx context.Context, rule string) ([]domain.Alert, error) {
+	exec := r.getter.DefaultTrOrDB(ctx, r.pool)
+
+	rows, err := exec.Query(ctx, alertUnnotifiedSQL, rule)
+	if err != nil {
+		return nil, fmt.Errorf("get unnotified alerts: %w", wrapScanError(err))
+	}
+	defer rows.Close()
+
+	var alerts []domain.Alert
+	for rows.Next() {
+		var a domain.Alert
+		if err := rows.Scan(&a.ID, &a.Rule, &a.Kind, &a.Location, &a.Key, &a.Message, &a.TriggeredAt); err != nil {
+			return nil, fmt.Errorf("scan unnotified alerts: %w", wrapScanError(err))
+		}
+		alerts = append(alerts, a)
+	}
+
+	return alerts, rows.Err()
+}
+
+func (r *AlertRepository) MarkNotified(ctx context.Context, id int64, at time.Time) error {
+	exec := r.getter.DefaultTrOrDB(ctx, r.pool)
+
+	if _, err := exec.Exec(ctx, alertMarkNotifiedSQL, id, at); err != nil {
+		return fmt.Errorf("mark alert notified: %w", wrapScanError(err))
+	}
+
+	return nil
+}
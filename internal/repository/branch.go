@@ -44,6 +44,15 @@ const (
 
 	getBranchesByCompanyName = `SELECT id FROM branches 
     WHERE name ILIKE '%' || $1 || '%'`
+
+	branchColumns = `id, COALESCE(name, ''), COALESCE(status, ''), COALESCE(type_id, 0), COALESCE(type_name, ''),
+		COALESCE(type_description, ''), COALESCE(token, ''), COALESCE(location_id::TEXT, ''), COALESCE(location_name, ''),
+		COALESCE(partner_id::TEXT, ''), COALESCE(partner_name, ''), COALESCE(partner_logo, '')`
+
+	getBranchesByIds = `SELECT ` + branchColumns + `
+	FROM branches
+	WHERE id = ANY($1)
+	ORDER BY id`
 )
 
 func (b *BranchRepository) CheckIfBranchExist(ctx context.Context, id int64) (bool, error) {
@@ -158,3 +167,38 @@ func (b *BranchRepository) GetBranchesByCompanyName(ctx context.Context, company
 
 	return branches, nil
 }
+
+func (b *BranchRepository) GetByIds(ctx context.Context, ids []domain.BranchId) ([]*domain.Branch, error) {
+	exec := b.getter.DefaultTrOrDB(ctx, b.pool)
+
+	rows, err := exec.Query(ctx, getBranchesByIds, ids)
+	if err != nil {
+		return nil, fmt.Errorf("get branches by ids: %w", wrapScanError(err))
+	}
+	defer rows.Close()
+
+	var branches []*domain.Branch
+	for rows.Next() {
+		var branch domain.Branch
+		err := rows.Scan(
+			&branch.ID,
+			&branch.Name,
+			&branch.Status,
+			&branch.TypeID,
+			&branch.TypeName,
+			&branch.TypeDescription,
+			&branch.Token,
+			&branch.LocationID,
+			&branch.LocationName,
+			&branch.PartnerID,
+			&branch.PartnerName,
+			&branch.PartnerLogo,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan branches by ids: %w", wrapScanError(err))
+		}
+		branches = append(branches, &branch)
+	}
+
+	return branches, rows.Err()
+}
@@ -2,6 +2,8 @@ package repository
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 
@@ -30,8 +32,8 @@ func NewCompanyCustomerRepository(
 
 const (
 	companyCustomerInsertSQL = `INSERT INTO company_customers
-    (company, user_id, full_name, phone, turnover, last_visit_date, visits_count, average_bill)
-    VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+    (company, user_id, full_name, phone, phone_normalized, turnover, last_visit_date, visits_count, average_bill)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
 
 	companyCustomerExistsSQL = `SELECT EXISTS (
 		SELECT 1 
@@ -39,8 +41,14 @@ const (
 		WHERE company = $1 AND user_id = $2 LIMIT 1)`
 
 	companyCustomerUpdateSQL = `UPDATE company_customers
-    SET full_name = $1, phone = $2, turnover = $3, last_visit_date = $4, visits_count = $5, average_bill = $6
-    WHERE company = $7 AND user_id = $8`
+    SET full_name = $1, phone = $2, phone_normalized = $3, turnover = $4, last_visit_date = $5, visits_count = $6, average_bill = $7
+    WHERE company = $8 AND user_id = $9`
+
+	companyCustomerSnapshotSQL = `INSERT INTO company_customer_snapshots
+	(company, taken_at, user_id, turnover, visits_count, average_bill, last_visit_date)
+	SELECT company, $2, user_id, turnover, visits_count, average_bill, last_visit_date
+	FROM customer_company_stats
+	WHERE company = $1`
 )
 
 func (r *CompanyCustomerRepository) Store(ctx context.Context, cc *domain.CompanyCustomer) error {
@@ -50,8 +58,9 @@ func (r *CompanyCustomerRepository) Store(ctx context.Context, cc *domain.Compan
 		cc.UserID,
 		cc.FullName,
 		cc.Phone,
+		nullString(cc.NormalizedPhone),
 		cc.Turnover,
-		cc.LastVisitDate,
+		nullTime(cc.LastVisitDate),
 		cc.VisitsCount,
 		cc.AverageBill,
 	)
@@ -76,8 +85,9 @@ func (r *CompanyCustomerRepository) UpdateByCompanyUserId(ctx context.Context, c
 	_, err := exec.Exec(ctx, companyCustomerUpdateSQL,
 		cc.FullName,
 		cc.Phone,
+		nullString(cc.NormalizedPhone),
 		cc.Turnover,
-		cc.LastVisitDate,
+		nullTime(cc.LastVisitDate),
 		cc.VisitsCount,
 		cc.AverageBill,
 		cc.Company,
@@ -88,3 +98,12 @@ func (r *CompanyCustomerRepository) UpdateByCompanyUserId(ctx context.Context, c
 	}
 	return nil
 }
+
+func (r *CompanyCustomerRepository) Snapshot(ctx context.Context, company string, takenAt time.Time) error {
+	exec := r.getter.DefaultTrOrDB(ctx, r.pool)
+	if _, err := exec.Exec(ctx, companyCustomerSnapshotSQL, company, takenAt); err != nil {
+		return fmt.Errorf("snapshot company customers: %w", wrapScanError(err))
+	}
+
+	return nil
+}
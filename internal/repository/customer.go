@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 
@@ -35,14 +36,31 @@ const (
 			WHERE id = $1 LIMIT 1)`
 
 	customerCreateSql = `INSERT INTO customers
-		(id, user_id, phone, birthday, full_name, orders_count) 
+		(id, user_id, phone, phone_normalized, birthday, full_name, orders_count) 
 	VALUES 
-		($1, $2, $3, $4, $5, $6)`
+		($1, $2, $3, $4, $5, $6, $7)`
 
 	findCustomerById = `SELECT
- 		id, user_id, phone, birthday, full_name, orders_count
+ 		id, user_id, phone, COALESCE(phone_normalized, ''), birthday, full_name, orders_count
 	FROM customers
 	WHERE id = $1`
+
+	findPhoneDuplicates = `SELECT
+		phone_normalized,
+		array_agg(DISTINCT user_id ORDER BY user_id),
+		array_agg(DISTINCT source ORDER BY source)
+	FROM (
+		SELECT phone_normalized, user_id::BIGINT AS user_id, 'customers' AS source
+		FROM customers
+		WHERE phone_normalized IS NOT NULL
+		UNION ALL
+		SELECT phone_normalized, user_id, 'company_customers:' || company AS source
+		FROM company_customers
+		WHERE phone_normalized IS NOT NULL AND user_id IS NOT NULL
+	) phones
+	GROUP BY phone_normalized
+	HAVING COUNT(DISTINCT user_id) > 1
+	ORDER BY COUNT(DISTINCT user_id) DESC, phone_normalized`
 )
 
 func (c *CustomerRepository) ExistsById(ctx context.Context, id domain.CustomerID) (bool, error) {
@@ -66,6 +84,7 @@ func (c *CustomerRepository) Create(ctx context.Context, customer *domain.Custom
 		customer.ID,
 		customer.UserID,
 		customer.Phone,
+		nullString(customer.NormalizedPhone),
 		customer.Birthday,
 		customer.FullName,
 		customer.OrderCount,
@@ -85,6 +104,7 @@ func (c *CustomerRepository) FindById(ctx context.Context, id domain.CustomerID)
 		&customer.ID,
 		&customer.UserID,
 		&customer.Phone,
+		&customer.NormalizedPhone,
 		&customer.Birthday,
 		&customer.FullName,
 		&customer.OrderCount,
@@ -95,3 +115,24 @@ func (c *CustomerRepository) FindById(ctx context.Context, id domain.CustomerID)
 
 	return customer, nil
 }
+
+func (c *CustomerRepository) FindPhoneDuplicates(ctx context.Context) ([]domain.PhoneDuplicate, error) {
+	exec := c.getter.DefaultTrOrDB(ctx, c.pool)
+
+	rows, err := exec.Query(ctx, findPhoneDuplicates)
+	if err != nil {
+		return nil, fmt.Errorf("find phone duplicates: %w", wrapScanError(err))
+	}
+	defer rows.Close()
+
+	var duplicates []domain.PhoneDuplicate
+	for rows.Next() {
+		var d domain.PhoneDuplicate
+		if err := rows.Scan(&d.Phone, &d.UserIDs, &d.Sources); err != nil {
+			return nil, fmt.Errorf("scan phone duplicates: %w", wrapScanError(err))
+		}
+		duplicates = append(duplicates, d)
+	}
+
+	return duplicates, rows.Err()
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ibookerke/choco_parser_go/internal/domain"
+	trmpgx "github.com/ibookerke/choco_parser_go/internal/pkg/pgx"
+	"github.com/ibookerke/choco_parser_go/internal/pkg/trm"
+)
+
+type CustomerProfileRepository struct {
+	pool   *pgxpool.Pool
+	getter *trmpgx.CtxGetter
+	trm    trm.Manager
+}
+
+func NewCustomerProfileRepository(
+	pool *pgxpool.Pool,
+	getter *trmpgx.CtxGetter,
+	trm trm.Manager,
+) *CustomerProfileRepository {
+	return &CustomerProfileRepository{
+		pool:   pool,
+		getter: getter,
+		trm:    trm,
+	}
+}
+
+const (
+	customerProfileFindSQL = `SELECT
+		user_id, full_name, phone, phone_normalized, birthday, orders_count,
+		companies_count, turnover, visits_count, last_visit_date
+	FROM customer_profiles
+	WHERE user_id = $1`
+
+	customerCompanyStatsFindSQL = `SELECT
+		COALESCE(company, ''), COALESCE(turnover, 0), COALESCE(visits_count, 0), COALESCE(average_bill, 0), last_visit_date
+	FROM customer_company_stats
+	WHERE user_id = $1
+	ORDER BY last_visit_date DESC NULLS LAST`
+)
+
+// FindByUserID returns the profile of the user together with the per-company stats.
+func (r *CustomerProfileRepository) FindByUserID(ctx context.Context, userID int64) (domain.CustomerProfile, error) {
+	exec := r.getter.DefaultTrOrDB(ctx, r.pool)
+
+	var (
+		profile   domain.CustomerProfile
+		lastVisit pgtype.Timestamptz
+	)
+	err := exec.QueryRow(ctx, customerProfileFindSQL, userID).Scan(
+		&profile.UserID,
+		&profile.FullName,
+		&profile.Phone,
+		&profile.NormalizedPhone,
+		&profile.Birthday,
+		&profile.OrdersCount,
+		&profile.CompaniesCount,
+		&profile.Turnover,
+		&profile.VisitsCount,
+		&lastVisit,
+	)
+	if err != nil {
+		return domain.CustomerProfile{}, fmt.Errorf("find customer profile: %w", wrapScanError(err))
+	}
+	profile.LastVisitDate = lastVisit.Time
+
+	rows, err := exec.Query(ctx, customerCompanyStatsFindSQL, userID)
+	if err != nil {
+		return domain.CustomerProfile{}, fmt.Errorf("find customer company stats: %w", wrapScanError(err))
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var stats domain.CompanyStats
+		err := rows.Scan(
+			&stats.Company,
+			&stats.Turnover,
+			&stats.VisitsCount,
+			&stats.AverageBill,
+			&lastVisit,
+		)
+		if err != nil {
+			return domain.CustomerProfile{}, fmt.Errorf("scan customer company stats: %w", wrapScanError(err))
+		}
+		stats.LastVisitDate = lastVisit.Time
+		profile.Companies = append(profile.Companies, stats)
+	}
+
+	return profile, rows.Err()
+}
@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ibookerke/choco_parser_go/internal/domain"
+	trmpgx "github.com/ibookerke/choco_parser_go/internal/pkg/pgx"
+	"github.com/ibookerke/choco_parser_go/internal/pkg/trm"
+)
+
+type CustomerSegmentRepository struct {
+	pool   *pgxpool.Pool
+	getter *trmpgx.CtxGetter
+	trm    trm.Manager
+}
+
+func NewCustomerSegmentRepository(
+	pool *pgxpool.Pool,
+	getter *trmpgx.CtxGetter,
+	trm trm.Manager,
+) *CustomerSegmentRepository {
+	return &CustomerSegmentRepository{
+		pool:   pool,
+		getter: getter,
+		trm:    trm,
+	}
+}
+
+const (
+	rfmStatsFromPaymentsSQL = `SELECT user_id, MAX(created_at), COUNT(*), SUM(amount)
+	FROM payments
+	WHERE branch_id = ANY($1) AND user_id IS NOT NULL AND type = 'pay'
+	GROUP BY user_id`
+
+	rfmStatsFromCompanyCustomersSQL = `SELECT user_id, last_visit_date, COALESCE(visits_count, 0), COALESCE(turnover, 0)
+	FROM customer_company_stats
+	WHERE company = $1 AND last_visit_date IS NOT NULL`
+
+	customerSegmentsDeleteRunSQL = `DELETE FROM customer_segments WHERE run_date = $1 AND company = $2`
+
+	customerSegmentInsertSQL = `INSERT INTO customer_segments
+    (run_date, company, user_id, recency_days, frequency, monetary, r_score, f_score, m_score, segment, source)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
+
+	segmentPhonesSQL = `WITH latest AS (
+        SELECT company, MAX(run_date) AS run_date
+        FROM customer_segments
+        WHERE $1 = '' OR company = $1
+        GROUP BY company
+    )
+    SELECT DISTINCT p.phone_normalized
+    FROM customer_segments s
+    JOIN latest l ON l.company = s.company AND l.run_date = s.run_date
+    JOIN customer_profiles p ON p.user_id = s.user_id
+    WHERE s.segment = $2 AND p.phone_normalized <> ''
+    ORDER BY p.phone_normalized`
+)
+
+func (r *CustomerSegmentRepository) RFMStatsFromPayments(ctx context.Context, branchIDs []domain.BranchId) ([]domain.RFMStats, error) {
+	return r.queryRFMStats(ctx, rfmStatsFromPaymentsSQL, branchIDs)
+}
+
+func (r *CustomerSegmentRepository) RFMStatsFromCompanyCustomers(ctx context.Context, company string) ([]domain.RFMStats, error) {
+	return r.queryRFMStats(ctx, rfmStatsFromCompanyCustomersSQL, company)
+}
+
+func (r *CustomerSegmentRepository) queryRFMStats(ctx context.Context, sql string, arg any) ([]domain.RFMStats, error) {
+	exec := r.getter.DefaultTrOrDB(ctx, r.pool)
+
+	rows, err := exec.Query(ctx, sql, arg)
+	if err != nil {
+		return nil, fmt.Errorf("get rfm stats: %w", wrapScanError(err))
+	}
+	defer rows.Close()
+
+	var stats []domain.RFMStats
+	for rows.Next() {
+		var s domain.RFMStats
+		if err := rows.Scan(&s.UserID, &s.LastVisit, &s.Frequency, &s.Monetary); err != nil {
+			return nil, fmt.Errorf("scan rfm stats: %w", wrapScanError(err))
+		}
+		stats = append(stats, s)
+	}
+
+	return stats, rows.Err()
+}
+
+func (r *CustomerSegmentRepository) ReplaceRun(
+	ctx context.Context,
+	runDate domain.Date,
+	company string,
+	segments []domain.CustomerSegment,
+) error {
+	exec := r.getter.DefaultTrOrDB(ctx, r.pool)
+
+	if _, err := exec.Exec(ctx, customerSegmentsDeleteRunSQL, runDate, company); err != nil {
+		return fmt.Errorf("delete segments run: %w", wrapScanError(err))
+	}
+
+	for _, s := range segments {
+		_, err := exec.Exec(ctx, customerSegmentInsertSQL,
+			s.RunDate,
+			s.Company,
+			s.UserID,
+			s.RecencyDays,
+			s.Frequency,
+			s.Monetary,
+			s.RScore,
+			s.FScore,
+			s.MScore,
+			s.Segment,
+			s.Source,
+		)
+		if err != nil {
+			return fmt.Errorf("store segment: %w", wrapScanError(err))
+		}
+	}
+
+	return nil
+}
+
+func (r *CustomerSegmentRepository) SegmentPhones(ctx context.Context, company, segment string) ([]string, error) {
+	exec := r.getter.DefaultTrOrDB(ctx, r.pool)
+
+	rows, err := exec.Query(ctx, segmentPhonesSQL, company, segment)
+	if err != nil {
+		return nil, fmt.Errorf("get segment phones: %w", wrapScanError(err))
+	}
+	defer rows.Close()
+
+	var phones []string
+	for rows.Next() {
+		var phone string
+		if err := rows.Scan(&phone); err != nil {
+			return nil, fmt.Errorf("scan segment phone: %w", wrapScanError(err))
+		}
+		phones = append(phones, phone)
+	}
+
+	return phones, rows.Err()
+}
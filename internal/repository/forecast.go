@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ibookerke/choco_parser_go/internal/domain"
+	trmpgx "github.com/ibookerke/choco_parser_go/internal/pkg/pgx"
+	"github.com/ibookerke/choco_parser_go/internal/pkg/trm"
+)
+
+type ForecastRepository struct {
+	pool   *pgxpool.Pool
+	getter *trmpgx.CtxGetter
+	trm    trm.Manager
+}
+
+func NewForecastRepository(
+	pool *pgxpool.Pool,
+	getter *trmpgx.CtxGetter,
+	trm trm.Manager,
+) *ForecastRepository {
+	return &ForecastRepository{
+		pool:   pool,
+		getter: getter,
+		trm:    trm,
+	}
+}
+
+const (
+	forecastStoreSQL = `INSERT INTO forecasts (location, made_on, day, predicted, lower_bound, upper_bound, model)
+	VALUES ($1, $2, $3, $4, $5, $6, $7)
+	ON CONFLICT (location, made_on, day) DO UPDATE
+	SET predicted = EXCLUDED.predicted,
+		lower_bound = EXCLUDED.lower_bound,
+		upper_bound = EXCLUDED.upper_bound,
+		model = EXCLUDED.model`
+)
+
+// Store should run inside a transaction so a run is stored whole.
+func (r *ForecastRepository) Store(ctx context.Context, forecasts []domain.Forecast) error {
+	exec := r.getter.DefaultTrOrDB(ctx, r.pool)
+
+	for _, f := range forecasts {
+		_, err := exec.Exec(ctx, forecastStoreSQL, f.Location, f.MadeOn, f.Day, f.Predicted, f.Lower, f.Upper, f.Model)
+		if err != nil {
+			return fmt.Errorf("store forecast: %w", wrapScanError(err))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ibookerke/choco_parser_go/internal/domain"
+	trmpgx "github.com/ibookerke/choco_parser_go/internal/pkg/pgx"
+	"github.com/ibookerke/choco_parser_go/internal/pkg/trm"
+)
+
+type LifecycleRepository struct {
+	pool   *pgxpool.Pool
+	getter *trmpgx.CtxGetter
+	trm    trm.Manager
+}
+
+func NewLifecycleRepository(
+	pool *pgxpool.Pool,
+	getter *trmpgx.CtxGetter,
+	trm trm.Manager,
+) *LifecycleRepository {
+	return &LifecycleRepository{
+		pool:   pool,
+		getter: getter,
+		trm:    trm,
+	}
+}
+
+const (
+	// lifecycleFactsSQL falls back to the last visit of company_customers
+	// when it is later than the stored payments.
+	lifecycleFactsSQL = `WITH pays AS (
+		SELECT
+			user_id,
+			created_at,
+			created_at - LAG(created_at) OVER (PARTITION BY user_id ORDER BY created_at) AS gap
+		FROM payments
+		WHERE type = 'pay' AND user_id IS NOT NULL
+	), facts AS (
+		SELECT
+			user_id,
+			MIN(created_at) AS first_at,
+			MAX(created_at) AS last_at,
+			MAX(created_at) FILTER (WHERE gap > make_interval(secs => $1)) AS last_return_at
+		FROM pays
+		GROUP BY user_id
+	), visits AS (
+		SELECT user_id, MAX(last_visit_date) AS last_visit_date
+		FROM customer_company_stats
+		GROUP BY user_id
+	)
+	SELECT f.user_id, f.first_at, GREATEST(f.last_at, v.last_visit_date), f.last_return_at
+	FROM facts f
+	LEFT JOIN visits v ON v.user_id = f.user_id`
+
+	lifecycleCurrentStagesSQL = `SELECT user_id, stage FROM customer_lifecycle`
+
+	lifecycleInsertEventSQL = `INSERT INTO customer_lifecycle_events (user_id, from_stage, to_stage, changed_at)
+	VALUES ($1, $2, $3, $4)`
+
+	lifecycleUpsertStageSQL = `INSERT INTO customer_lifecycle (user_id, stage, since)
+	VALUES ($1, $2, $3)
+	ON CONFLICT (user_id) DO UPDATE
+	SET stage = EXCLUDED.stage, since = EXCLUDED.since`
+
+	lifecycleTransitionCountsSQL = `SELECT
+		(changed_at AT TIME ZONE $3)::DATE AS day,
+		COALESCE(from_stage, ''),
+		to_stage,
+		COUNT(*)
+	FROM customer_lifecycle_events
+	WHERE changed_at >= $1::DATE::TIMESTAMP AT TIME ZONE $3
+		AND changed_at < ($2::DATE + 1)::TIMESTAMP AT TIME ZONE $3
+	GROUP BY 1, 2, 3
+	ORDER BY 1, 2, 3`
+)
+
+func (r *LifecycleRepository) Facts(ctx context.Context, lapsedAfter time.Duration) ([]domain.LifecycleFacts, error) {
+	exec := r.getter.DefaultTrOrDB(ctx, r.pool)
+
+	rows, err := exec.Query(ctx, lifecycleFactsSQL, lapsedAfter.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("get lifecycle facts: %w", wrapScanError(err))
+	}
+	defer rows.Close()
+
+	var facts []domain.LifecycleFacts
+	for rows.Next() {
+		var (
+			f            domain.LifecycleFacts
+			lastReturnAt pgtype.Timestamptz
+		)
+		if err := rows.Scan(&f.UserID, &f.FirstAt, &f.LastAt, &lastReturnAt); err != nil {
+			return nil, fmt.Errorf("scan lifecycle facts: %w", wrapScanError(err))
+		}
+		f.LastReturnAt = lastReturnAt.Time
+		facts = append(facts, f)
+	}
+
+	return facts, rows.Err()
+}
+
+func (r *LifecycleRepository) CurrentStages(ctx context.Context) (map[int64]string, error) {
+	exec := r.getter.DefaultTrOrDB(ctx, r.pool)
+
+	rows, err := exec.Query(ctx, lifecycleCurrentStagesSQL)
+	if err != nil {
+		return nil, fmt.Errorf("get current lifecycle stages: %w", wrapScanError(err))
+	}
+	defer rows.Close()
+
+	stages := make(map[int64]string)
+	for rows.Next() {
+		var (
+			userID int64
+			stage  string
+		)
+		if err := rows.Scan(&userID, &stage); err != nil {
+			return nil, fmt.Errorf("scan current lifecycle stages: %w", wrapScanError(err))
+		}
+		stages[userID] = stage
+	}
+
+	return stages, rows.Err()
+}
+
+func (r *LifecycleRepository) SetStage(ctx context.Context, userID int64, stage string, since time.Time) error {
+	exec := r.getter.DefaultTrOrDB(ctx, r.pool)
+
+	if _, err := exec.Exec(ctx, lifecycleUpsertStageSQL, userID, stage, since); err != nil {
+		return fmt.Errorf("upsert lifecycle stage: %w", wrapScanError(err))
+	}
+
+	return nil
+}
+
+// RecordEvent should run inside a transaction so the current stage always matches the latest event.
+func (r *LifecycleRepository) RecordEvent(ctx context.Context, event *domain.LifecycleEvent) error {
+	exec := r.getter.DefaultTrOrDB(ctx, r.pool)
+
+	_, err := exec.Exec(ctx, lifecycleInsertEventSQL, event.UserID, nullString(event.From), event.To, event.ChangedAt)
+	if err != nil {
+		return fmt.Errorf("insert lifecycle event: %w", wrapScanError(err))
+	}
+
+	if _, err := exec.Exec(ctx, lifecycleUpsertStageSQL, event.UserID, event.To, event.ChangedAt); err != nil {
+		return fmt.Errorf("upsert lifecycle stage: %w", wrapScanError(err))
+	}
+
+	return nil
+}
+
+func (r *LifecycleRepository) TransitionCounts(
+	ctx context.Context,
+	from, to domain.Date,
+	zone string,
+) ([]domain.LifecycleTransitionCount, error) {
+	exec := r.getter.DefaultTrOrDB(ctx, r.pool)
+
+	rows, err := exec.Query(ctx, lifecycleTransitionCountsSQL, from, to, zone)
+	if err != nil {
+		return nil, fmt.Errorf("get lifecycle transition counts: %w", wrapScanError(err))
+	}
+	defer rows.Close()
+
+	var counts []domain.LifecycleTransitionCount
+	for rows.Next() {
+		var c domain.LifecycleTransitionCount
+		if err := rows.Scan(&c.Day, &c.From, &c.To, &c.Customers); err != nil {
+			return nil, fmt.Errorf("scan lifecycle transition counts: %w", wrapScanError(err))
+		}
+		counts = append(counts, c)
+	}
+
+	return counts, rows.Err()
+}
@@ -2,7 +2,10 @@ package repository
 
 import (
 	"context"
+	"fmt"
+	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/ibookerke/choco_parser_go/internal/domain"
@@ -30,24 +33,55 @@ func NewPaymentRepository(
 
 const (
 	paymentCreateSql = `INSERT INTO payments
-    (id, created_by, type, amount, discount_amount, created_at, location_title, location_partner_id)
-    values ($1, $2, $3, $4, $5, $6, $7, $8)`
+    (id, user_id, created_by, type, amount, discount_amount, created_at, location_title, location_partner_id, branch_id, location_id)
+    values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
 
 	paymentExistsById = `SELECT 
 		EXISTS ( SELECT 1 
 			FROM payments 
 			WHERE id = $1 LIMIT 1)`
 
-	paymentFindById = `SELECT
-		id, created_by, type, amount, discount_amount, created_at, location_title, location_partner_id
+	paymentColumns = `id, COALESCE(user_id, 0), COALESCE(created_by, 0), type, amount, discount_amount, created_at,
+		COALESCE(location_title, ''), COALESCE(location_partner_id::TEXT, ''), COALESCE(branch_id, 0), COALESCE(location_id::TEXT, ''),
+		COALESCE(refund_of, 0), refunded_amount`
+
+	paymentFindById = `SELECT ` + paymentColumns + `
 	FROM payments
 	WHERE id = $1`
+
+	paymentFindByUserId = `SELECT ` + paymentColumns + `
+	FROM payments
+	WHERE user_id = $1
+	ORDER BY created_at DESC
+	LIMIT $2`
+
+	paymentFindBetween = `SELECT ` + paymentColumns + `
+	FROM payments
+	WHERE created_at >= $1 AND created_at < $2
+	ORDER BY created_at, id`
+
+	paymentFindUnlinkedRefunds = `SELECT ` + paymentColumns + `
+	FROM payments
+	WHERE type = 'refund' AND refund_of IS NULL AND user_id IS NOT NULL AND created_at >= $1
+	ORDER BY created_at, id`
+
+	paymentFindRefundCandidates = `SELECT ` + paymentColumns + `
+	FROM payments
+	WHERE user_id = $1 AND type = 'pay' AND created_at BETWEEN $2 AND $3`
+
+	// payments stored before user_id existed get it on their next sync, an erased user's stay NULL
+	paymentFillUserID = `UPDATE payments SET user_id = $2 WHERE id = $1 AND user_id IS NULL`
+
+	paymentSetRefundOf = `UPDATE payments SET refund_of = $2 WHERE id = $1`
+
+	paymentAddRefundedAmount = `UPDATE payments SET refunded_amount = refunded_amount + $2 WHERE id = $1`
 )
 
 func (p *PaymentRepository) Create(ctx context.Context, payment *domain.Payment) (*domain.Payment, error) {
 	exec := p.getter.DefaultTrOrDB(ctx, p.pool)
 	_, err := exec.Exec(ctx, paymentCreateSql,
 		payment.ID,
+		nullInt64(payment.UserID),
 		payment.CreatedBy,
 		payment.Type,
 		payment.Amount,
@@ -55,6 +89,8 @@ func (p *PaymentRepository) Create(ctx context.Context, payment *domain.Payment)
 		payment.CreatedAt,
 		payment.LocationTitle,
 		payment.LocationPartnerID,
+		nullInt64(int64(payment.BranchID)),
+		nullString(payment.LocationID),
 	)
 	if err != nil {
 		return nil, err
@@ -73,11 +109,117 @@ func (p *PaymentRepository) ExistsById(ctx context.Context, id domain.PaymentID)
 	return exists, nil
 }
 
+func (p *PaymentRepository) FillUserID(ctx context.Context, id domain.PaymentID, userID int64) error {
+	exec := p.getter.DefaultTrOrDB(ctx, p.pool)
+	if _, err := exec.Exec(ctx, paymentFillUserID, id, userID); err != nil {
+		return fmt.Errorf("fill payment user id: %w", wrapScanError(err))
+	}
+	return nil
+}
+
 func (p *PaymentRepository) FindById(ctx context.Context, id domain.PaymentID) (*domain.Payment, error) {
 	exec := p.getter.DefaultTrOrDB(ctx, p.pool)
+	payment, err := scanPayment(exec.QueryRow(ctx, paymentFindById, id))
+	if err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+func (p *PaymentRepository) FindByUserID(ctx context.Context, userID int64, limit int) ([]domain.Payment, error) {
+	exec := p.getter.DefaultTrOrDB(ctx, p.pool)
+
+	rows, err := exec.Query(ctx, paymentFindByUserId, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("find payments by user id: %w", wrapScanError(err))
+	}
+	defer rows.Close()
+
+	var payments []domain.Payment
+	for rows.Next() {
+		payment, err := scanPayment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan payments by user id: %w", wrapScanError(err))
+		}
+		payments = append(payments, payment)
+	}
+
+	return payments, rows.Err()
+}
+
+// FindBetween lists the payments made from since up to, not including, until.
+func (p *PaymentRepository) FindBetween(ctx context.Context, since, until time.Time) ([]domain.Payment, error) {
+	exec := p.getter.DefaultTrOrDB(ctx, p.pool)
+
+	rows, err := exec.Query(ctx, paymentFindBetween, since, until)
+	if err != nil {
+		return nil, fmt.Errorf("find payments between: %w", wrapScanError(err))
+	}
+
+	return collectPayments(rows, "payments between")
+}
+
+func (p *PaymentRepository) FindUnlinkedRefunds(ctx context.Context, since time.Time) ([]domain.Payment, error) {
+	exec := p.getter.DefaultTrOrDB(ctx, p.pool)
+
+	rows, err := exec.Query(ctx, paymentFindUnlinkedRefunds, since)
+	if err != nil {
+		return nil, fmt.Errorf("find unlinked refunds: %w", wrapScanError(err))
+	}
+
+	return collectPayments(rows, "unlinked refunds")
+}
+
+func (p *PaymentRepository) FindRefundCandidates(
+	ctx context.Context,
+	userID int64,
+	since, until time.Time,
+) ([]domain.Payment, error) {
+	exec := p.getter.DefaultTrOrDB(ctx, p.pool)
+
+	rows, err := exec.Query(ctx, paymentFindRefundCandidates, userID, since, until)
+	if err != nil {
+		return nil, fmt.Errorf("find refund candidates: %w", wrapScanError(err))
+	}
+
+	return collectPayments(rows, "refund candidates")
+}
+
+// LinkRefund should run inside a transaction so the refunded amount never drifts from the links.
+func (p *PaymentRepository) LinkRefund(ctx context.Context, refundID, paymentID domain.PaymentID, amount domain.Money) error {
+	exec := p.getter.DefaultTrOrDB(ctx, p.pool)
+
+	if _, err := exec.Exec(ctx, paymentSetRefundOf, refundID, paymentID); err != nil {
+		return fmt.Errorf("set refund of: %w", wrapScanError(err))
+	}
+
+	if _, err := exec.Exec(ctx, paymentAddRefundedAmount, paymentID, amount); err != nil {
+		return fmt.Errorf("add refunded amount: %w", wrapScanError(err))
+	}
+
+	return nil
+}
+
+func collectPayments(rows pgx.Rows, what string) ([]domain.Payment, error) {
+	defer rows.Close()
+
+	var payments []domain.Payment
+	for rows.Next() {
+		payment, err := scanPayment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan %s: %w", what, wrapScanError(err))
+		}
+		payments = append(payments, payment)
+	}
+
+	return payments, rows.Err()
+}
+
+func scanPayment(row pgx.Row) (domain.Payment, error) {
 	var payment domain.Payment
-	err := exec.QueryRow(ctx, paymentFindById, id).Scan(
+	err := row.Scan(
 		&payment.ID,
+		&payment.UserID,
 		&payment.CreatedBy,
 		&payment.Type,
 		&payment.Amount,
@@ -85,9 +227,11 @@ func (p *PaymentRepository) FindById(ctx context.Context, id domain.PaymentID) (
 		&payment.CreatedAt,
 		&payment.LocationTitle,
 		&payment.LocationPartnerID,
+		&payment.BranchID,
+		&payment.LocationID,
+		&payment.RefundOf,
+		&payment.RefundedAmount,
 	)
-	if err != nil {
-		return nil, err
-	}
-	return &payment, nil
+
+	return payment, err
 }
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ibookerke/choco_parser_go/internal/domain"
+	trmpgx "github.com/ibookerke/choco_parser_go/internal/pkg/pgx"
+	"github.com/ibookerke/choco_parser_go/internal/pkg/trm"
+)
+
+type PrivacyRepository struct {
+	pool   *pgxpool.Pool
+	getter *trmpgx.CtxGetter
+	trm    trm.Manager
+}
+
+func NewPrivacyRepository(
+	pool *pgxpool.Pool,
+	getter *trmpgx.CtxGetter,
+	trm trm.Manager,
+) *PrivacyRepository {
+	return &PrivacyRepository{
+		pool:   pool,
+		getter: getter,
+		trm:    trm,
+	}
+}
+
+const (
+	eraseCustomersSQL = `DELETE FROM customers WHERE user_id = $1 OR id = $1`
+
+	eraseCompanyCustomersSQL = `DELETE FROM company_customers WHERE user_id = $1`
+
+	eraseCompanyCustomerSnapshotsSQL = `DELETE FROM company_customer_snapshots WHERE user_id = $1`
+
+	eraseReviewsSQL = `DELETE FROM payment_reviews WHERE user_id = $1`
+
+	anonymizePaymentsSQL = `UPDATE payments
+		SET user_id = CASE WHEN user_id = $1 THEN NULL ELSE user_id END,
+			created_by = CASE WHEN created_by = $1 THEN NULL ELSE created_by END
+		WHERE user_id = $1 OR created_by = $1`
+
+	eraseCustomerSegmentsSQL = `DELETE FROM customer_segments WHERE user_id = $1`
+
+	eraseStaffSQL = `DELETE FROM staff WHERE id = $1`
+
+	eraseLifecycleEventsSQL = `DELETE FROM customer_lifecycle_events WHERE user_id = $1`
+
+	eraseLifecycleSQL = `DELETE FROM customer_lifecycle WHERE user_id = $1`
+
+	eraseSegmentMembersSQL = `DELETE FROM segment_members WHERE user_id = $1`
+
+	eraseSegmentMemberEventsSQL = `DELETE FROM segment_member_events WHERE user_id = $1`
+
+	recordErasureSQL = `INSERT INTO privacy_erasures
+		(user_id, erased_at, customers_deleted, company_customers_deleted, payments_anonymized, reviews_deleted)
+	VALUES ($1, $2, $3, $4, $5, $6)
+	ON CONFLICT (user_id) DO UPDATE
+	SET erased_at = EXCLUDED.erased_at,
+		customers_deleted = privacy_erasures.customers_deleted + EXCLUDED.customers_deleted,
+		company_customers_deleted = privacy_erasures.company_customers_deleted + EXCLUDED.company_customers_deleted,
+		payments_anonymized = privacy_erasures.payments_anonymized + EXCLUDED.payments_anonymized,
+		reviews_deleted = privacy_erasures.reviews_deleted + EXCLUDED.reviews_deleted`
+
+	erasedUserIdsSQL = `SELECT user_id FROM privacy_erasures`
+)
+
+// EraseUser should run inside a transaction so the erasure is all or nothing.
+func (r *PrivacyRepository) EraseUser(ctx context.Context, userID int64) (domain.Erasure, error) {
+	exec := r.getter.DefaultTrOrDB(ctx, r.pool)
+	erasure := domain.Erasure{UserID: userID}
+
+	steps := []struct {
+		sql   string
+		count *int64
+	}{
+		{sql: eraseCustomersSQL, count: &erasure.CustomersDeleted},
+		{sql: eraseCompanyCustomersSQL, count: &erasure.CompanyCustomersDeleted},
+		{sql: eraseReviewsSQL, count: &erasure.ReviewsDeleted},
+		{sql: anonymizePaymentsSQL, count: &erasure.PaymentsAnonymized},
+		// snapshot, segment, staff and lifecycle rows are derived from the data above, they aren't worth their own counters
+		{sql: eraseCompanyCustomerSnapshotsSQL, count: new(int64)},
+		{sql: eraseCustomerSegmentsSQL, count: new(int64)},
+		{sql: eraseStaffSQL, count: new(int64)},
+		{sql: eraseLifecycleEventsSQL, count: new(int64)},
+		{sql: eraseLifecycleSQL, count: new(int64)},
+		{sql: eraseSegmentMembersSQL, count: new(int64)},
+		{sql: eraseSegmentMemberEventsSQL, count: new(int64)},
+	}
+
+	for _, step := range steps {
+		tag, err := exec.Exec(ctx, step.sql, userID)
+		if err != nil {
+			return domain.Erasure{}, fmt.Errorf("erase user: %w", wrapScanError(err))
+		}
+		*step.count = tag.RowsAffected()
+	}
+
+	return erasure, nil
+}
+
+func (r *PrivacyRepository) RecordErasure(ctx context.Context, erasure *domain.Erasure) error {
+	exec := r.getter.DefaultTrOrDB(ctx, r.pool)
+	_, err := exec.Exec(ctx, recordErasureSQL,
+		erasure.UserID,
+		erasure.ErasedAt,
+		erasure.CustomersDeleted,
+		erasure.CompanyCustomersDeleted,
+		erasure.PaymentsAnonymized,
+		erasure.ReviewsDeleted,
+	)
+	if err != nil {
+		return fmt.Errorf("record erasure: %w", wrapScanError(err))
+	}
+	return nil
+}
+
+func (r *PrivacyRepository) ErasedUserIDs(ctx context.Context) ([]int64, error) {
+	exec := r.getter.DefaultTrOrDB(ctx, r.pool)
+
+	rows, err := exec.Query(ctx, erasedUserIdsSQL)
+	if err != nil {
+		return nil, fmt.Errorf("get erased user ids: %w", wrapScanError(err))
+	}
+
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+	if err != nil {
+		return nil, fmt.Errorf("scan erased user ids: %w", wrapScanError(err))
+	}
+
+	return ids, nil
+}
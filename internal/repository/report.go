@@ -0,0 +1,506 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ibookerke/choco_parser_go/internal/domain"
+	trmpgx "github.com/ibookerke/choco_parser_go/internal/pkg/pgx"
+	"github.com/ibookerke/choco_parser_go/internal/pkg/trm"
+)
+
+type ReportRepository struct {
+	pool   *pgxpool.Pool
+	getter *trmpgx.CtxGetter
+	trm    trm.Manager
+}
+
+func NewReportRepository(
+	pool *pgxpool.Pool,
+	getter *trmpgx.CtxGetter,
+	trm trm.Manager,
+) *ReportRepository {
+	return &ReportRepository{
+		pool:   pool,
+		getter: getter,
+		trm:    trm,
+	}
+}
+
+const (
+	cohortCountsSQL = `WITH visits AS (
+		SELECT user_id, date_trunc('month', created_at AT TIME ZONE $2)::DATE AS month
+		FROM payments
+		WHERE branch_id = ANY($1) AND user_id IS NOT NULL AND type = 'pay'
+		GROUP BY user_id, month
+	), cohorts AS (
+		SELECT user_id, MIN(month) AS cohort
+		FROM visits
+		GROUP BY user_id
+	)
+	SELECT
+		c.cohort,
+		((EXTRACT(YEAR FROM v.month) - EXTRACT(YEAR FROM c.cohort)) * 12
+			+ EXTRACT(MONTH FROM v.month) - EXTRACT(MONTH FROM c.cohort))::INT AS month_offset,
+		COUNT(*)
+	FROM visits v
+	JOIN cohorts c ON c.user_id = v.user_id
+	GROUP BY c.cohort, month_offset
+	ORDER BY c.cohort, month_offset`
+
+	churnCandidatesSQL = `WITH intervals AS (
+		SELECT
+			user_id,
+			EXTRACT(EPOCH FROM MAX(created_at) - MIN(created_at)) / 86400 / (COUNT(*) - 1) AS interval_days
+		FROM payments
+		WHERE branch_id = ANY($2) AND user_id IS NOT NULL AND type = 'pay'
+		GROUP BY user_id
+		HAVING COUNT(*) > 1
+	), visit_intervals AS (
+		SELECT
+			user_id,
+			EXTRACT(EPOCH FROM MAX(last_visit_date) - MIN(last_visit_date)) / 86400
+				/ (MAX(visits_count) - MIN(visits_count)) AS interval_days
+		FROM company_customers
+		WHERE company = $1 AND user_id IS NOT NULL AND last_visit_date IS NOT NULL
+		GROUP BY user_id
+		HAVING MAX(visits_count) > MIN(visits_count)
+	)
+	SELECT
+		s.user_id, COALESCE(s.full_name, ''), COALESCE(s.phone, ''), COALESCE(s.turnover, 0),
+		COALESCE(s.visits_count, 0), COALESCE(s.average_bill, 0), s.last_visit_date,
+		i.interval_days::FLOAT8, v.interval_days::FLOAT8
+	FROM customer_company_stats s
+	LEFT JOIN intervals i ON i.user_id = s.user_id
+	LEFT JOIN visit_intervals v ON v.user_id = s.user_id
+	WHERE s.company = $1
+		AND s.last_visit_date < $3
+		AND COALESCE(s.turnover, 0) >= $4`
+
+	birthdayCandidatesSQL = `SELECT
+		s.user_id,
+		COALESCE(NULLIF(c.full_name, ''), s.full_name, ''),
+		COALESCE(c.phone_normalized, s.phone_normalized, NULLIF(c.phone, ''), s.phone, ''),
+		c.birthday,
+		COALESCE(s.visits_count, 0),
+		COALESCE(s.turnover, 0)
+	FROM customer_company_stats s
+	JOIN LATERAL (
+		SELECT full_name, phone, phone_normalized, birthday
+		FROM customers
+		WHERE user_id = s.user_id AND birthday IS NOT NULL
+		ORDER BY id DESC
+		LIMIT 1
+	) c ON TRUE
+	WHERE s.company = $1`
+
+	// payments attributed to a location are grouped by location_id under the title of its main terminal,
+	// the rest by location title
+	discountCellsSQL = `WITH pays AS (
+		SELECT
+			p.user_id,
+			COALESCE(p.location_id::TEXT, '') AS location_id,
+			CASE WHEN p.location_id IS NULL THEN COALESCE(p.location_title, '') ELSE '' END AS title_key,
+			COALESCE(l.location_name, p.location_title, '') AS location,
+			date_trunc('month', p.created_at AT TIME ZONE $3)::DATE AS month,
+			p.created_at,
+			p.type,
+			COALESCE(` + netAmountSQL + `, 0) AS amount,
+			CASE WHEN p.type = 'pay' THEN COALESCE(p.discount_amount, 0) ELSE 0 END AS discount_amount
+		FROM payments p
+		LEFT JOIN (
+			SELECT DISTINCT ON (location_id) location_id, NULLIF(location_name, '') AS location_name
+			FROM branches
+			WHERE location_id IS NOT NULL
+			ORDER BY location_id, (type_name = 'main') DESC, id
+		) l ON l.location_id = p.location_id
+		WHERE (p.type = 'pay' OR p.type = 'refund' AND p.refund_of IS NULL)
+			AND p.created_at >= $1::DATE::TIMESTAMP AT TIME ZONE $3
+			AND p.created_at < ($2::DATE + 1)::TIMESTAMP AT TIME ZONE $3
+	), totals AS (
+		SELECT
+			location_id,
+			title_key,
+			month,
+			MAX(location) AS location,
+			COUNT(*) FILTER (WHERE type = 'pay') AS payments,
+			COUNT(*) FILTER (WHERE type = 'pay' AND discount_amount > 0) AS discounted_payments,
+			SUM(amount) AS amount,
+			SUM(discount_amount) AS discounts
+		FROM pays
+		GROUP BY location_id, title_key, month
+	), customers AS (
+		SELECT location_id, title_key, month, user_id, bool_or(discount_amount > 0) AS discounted, MAX(created_at) AS last_visit
+		FROM pays
+		WHERE user_id IS NOT NULL AND type = 'pay'
+		GROUP BY location_id, title_key, month, user_id
+	), returns AS (
+		SELECT
+			c.location_id,
+			c.title_key,
+			c.month,
+			c.discounted,
+			COUNT(*) AS customers,
+			COUNT(*) FILTER (WHERE EXISTS (
+				SELECT 1
+				FROM payments n
+				WHERE n.user_id = c.user_id
+					AND CASE
+						WHEN c.location_id <> '' THEN n.location_id::TEXT = c.location_id
+						ELSE n.location_id IS NULL AND COALESCE(n.location_title, '') = c.title_key
+					END
+					AND n.type = 'pay'
+					AND n.created_at > c.last_visit
+					AND n.created_at <= c.last_visit + make_interval(days => $4)
+			)) AS returned
+		FROM customers c
+		GROUP BY c.location_id, c.title_key, c.month, c.discounted
+	)
+	SELECT
+		t.location_id, t.location, t.month, t.payments, t.discounted_payments, t.amount, t.discounts,
+		COALESCE(d.customers, 0), COALESCE(d.returned, 0), COALESCE(o.customers, 0), COALESCE(o.returned, 0)
+	FROM totals t
+	LEFT JOIN returns d
+		ON d.location_id = t.location_id AND d.title_key = t.title_key AND d.month = t.month AND d.discounted
+	LEFT JOIN returns o
+		ON o.location_id = t.location_id AND o.title_key = t.title_key AND o.month = t.month AND NOT o.discounted
+	ORDER BY t.location, t.location_id, t.month`
+
+	overlapCustomersSQL = `SELECT
+		a.user_id,
+		COALESCE(NULLIF(b.full_name, ''), a.full_name, ''),
+		COALESCE(b.phone_normalized, a.phone_normalized, b.phone, a.phone, ''),
+		COALESCE(a.turnover, 0),
+		COALESCE(b.turnover, 0),
+		COALESCE(a.visits_count, 0),
+		COALESCE(b.visits_count, 0),
+		a.last_visit_date,
+		b.last_visit_date
+	FROM customer_company_stats a
+	JOIN customer_company_stats b ON b.user_id = a.user_id AND b.company = $2
+	WHERE a.company = $1
+	ORDER BY COALESCE(a.turnover, 0) + COALESCE(b.turnover, 0) DESC`
+
+	switchCandidatesSQL = `WITH runs AS (
+		SELECT company, taken_at, ROW_NUMBER() OVER (PARTITION BY company ORDER BY taken_at DESC) AS run
+		FROM (
+			SELECT DISTINCT company, taken_at
+			FROM company_customer_snapshots
+			WHERE company IN ($1, $2)
+		) t
+	), states AS (
+		SELECT
+			s.user_id,
+			r.run,
+			MAX(s.last_visit_date) FILTER (WHERE s.company = $1) AS last_visit_a,
+			MAX(s.last_visit_date) FILTER (WHERE s.company = $2) AS last_visit_b
+		FROM company_customer_snapshots s
+		JOIN runs r ON r.company = s.company AND r.taken_at = s.taken_at
+		WHERE r.run <= 2
+		GROUP BY s.user_id, r.run
+	)
+	SELECT
+		c.user_id,
+		COALESCE(p.full_name, ''),
+		COALESCE(p.phone_normalized, p.phone, ''),
+		prev.last_visit_a,
+		prev.last_visit_b,
+		c.last_visit_a,
+		c.last_visit_b
+	FROM states c
+	JOIN states prev ON prev.user_id = c.user_id AND prev.run = 2
+	LEFT JOIN customer_profiles p ON p.user_id = c.user_id
+	WHERE c.run = 1
+		AND (c.last_visit_a IS NOT NULL OR prev.last_visit_a IS NOT NULL)
+		AND (c.last_visit_b IS NOT NULL OR prev.last_visit_b IS NOT NULL)`
+
+	heatmapCellsSQL = `SELECT
+		EXTRACT(ISODOW FROM created_at AT TIME ZONE $4)::INT AS weekday,
+		EXTRACT(HOUR FROM created_at AT TIME ZONE $4)::INT AS hour,
+		COUNT(*) FILTER (WHERE type = 'pay'),
+		COALESCE(SUM(` + netAmountSQL + `), 0)
+	FROM payments
+	WHERE (type = 'pay' OR type = 'refund' AND refund_of IS NULL)
+		AND (location_id::TEXT = $1 OR location_title ILIKE '%' || $1 || '%')
+		AND created_at >= $2::DATE::TIMESTAMP AT TIME ZONE $4
+		AND created_at < ($3::DATE + 1)::TIMESTAMP AT TIME ZONE $4
+	GROUP BY weekday, hour
+	ORDER BY weekday, hour`
+
+	staffActivitySQL = `SELECT
+		date_trunc($5, p.created_at AT TIME ZONE $4)::DATE AS period,
+		p.created_by,
+		COALESCE(s.name, ''),
+		COUNT(*) FILTER (WHERE p.type = 'pay'),
+		COALESCE(SUM(p.amount) FILTER (WHERE p.type = 'pay'), 0),
+		COALESCE(SUM(p.discount_amount) FILTER (WHERE p.type = 'pay'), 0),
+		COUNT(*) FILTER (WHERE p.type = 'refund'),
+		COALESCE(SUM(ABS(p.amount)) FILTER (WHERE p.type = 'refund'), 0)
+	FROM payments p
+	LEFT JOIN staff s ON s.id = p.created_by
+	WHERE p.created_by IS NOT NULL AND p.created_by <> 0
+		AND (p.location_id::TEXT = $1 OR p.location_title ILIKE '%' || $1 || '%')
+		AND p.created_at >= $2::DATE::TIMESTAMP AT TIME ZONE $4
+		AND p.created_at < ($3::DATE + 1)::TIMESTAMP AT TIME ZONE $4
+	GROUP BY period, p.created_by, s.name
+	ORDER BY period, p.created_by`
+)
+
+func (r *ReportRepository) CohortCounts(ctx context.Context, branchIDs []domain.BranchId, zone string) ([]domain.CohortCell, error) {
+	exec := r.getter.DefaultTrOrDB(ctx, r.pool)
+
+	rows, err := exec.Query(ctx, cohortCountsSQL, branchIDs, zone)
+	if err != nil {
+		return nil, fmt.Errorf("get cohort counts: %w", wrapScanError(err))
+	}
+	defer rows.Close()
+
+	var cells []domain.CohortCell
+	for rows.Next() {
+		var c domain.CohortCell
+		if err := rows.Scan(&c.Cohort, &c.Offset, &c.Customers); err != nil {
+			return nil, fmt.Errorf("scan cohort counts: %w", wrapScanError(err))
+		}
+		cells = append(cells, c)
+	}
+
+	return cells, rows.Err()
+}
+
+func (r *ReportRepository) ChurnCandidates(
+	ctx context.Context,
+	company string,
+	branchIDs []domain.BranchId,
+	inactiveSince time.Time,
+	minTurnover domain.Money,
+) ([]domain.ChurnCandidate, error) {
+	exec := r.getter.DefaultTrOrDB(ctx, r.pool)
+
+	rows, err := exec.Query(ctx, churnCandidatesSQL, company, branchIDs, inactiveSince, minTurnover)
+	if err != nil {
+		return nil, fmt.Errorf("get churn candidates: %w", wrapScanError(err))
+	}
+	defer rows.Close()
+
+	var candidates []domain.ChurnCandidate
+	for rows.Next() {
+		var c domain.ChurnCandidate
+		err := rows.Scan(
+			&c.UserID,
+			&c.FullName,
+			&c.Phone,
+			&c.Turnover,
+			&c.VisitsCount,
+			&c.AverageBill,
+			&c.LastVisit,
+			&c.IntervalDays,
+			&c.VisitIntervalDays,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan churn candidates: %w", wrapScanError(err))
+		}
+		candidates = append(candidates, c)
+	}
+
+	return candidates, rows.Err()
+}
+
+func (r *ReportRepository) BirthdayCandidates(ctx context.Context, company string) ([]domain.BirthdayCandidate, error) {
+	exec := r.getter.DefaultTrOrDB(ctx, r.pool)
+
+	rows, err := exec.Query(ctx, birthdayCandidatesSQL, company)
+	if err != nil {
+		return nil, fmt.Errorf("get birthday candidates: %w", wrapScanError(err))
+	}
+	defer rows.Close()
+
+	var candidates []domain.BirthdayCandidate
+	for rows.Next() {
+		var c domain.BirthdayCandidate
+		err := rows.Scan(
+			&c.UserID,
+			&c.FullName,
+			&c.Phone,
+			&c.Birthday,
+			&c.VisitsCount,
+			&c.Turnover,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan birthday candidates: %w", wrapScanError(err))
+		}
+		candidates = append(candidates, c)
+	}
+
+	return candidates, rows.Err()
+}
+
+func (r *ReportRepository) DiscountCells(
+	ctx context.Context,
+	from, to domain.Date,
+	zone string,
+	returnDays int,
+) ([]domain.DiscountCell, error) {
+	exec := r.getter.DefaultTrOrDB(ctx, r.pool)
+
+	rows, err := exec.Query(ctx, discountCellsSQL, from, to, zone, returnDays)
+	if err != nil {
+		return nil, fmt.Errorf("get discount cells: %w", wrapScanError(err))
+	}
+	defer rows.Close()
+
+	var cells []domain.DiscountCell
+	for rows.Next() {
+		var c domain.DiscountCell
+		err := rows.Scan(
+			&c.LocationID,
+			&c.Location,
+			&c.Month,
+			&c.Payments,
+			&c.DiscountedPayments,
+			&c.Amount,
+			&c.Discounts,
+			&c.DiscountedCustomers,
+			&c.DiscountedReturned,
+			&c.OtherCustomers,
+			&c.OtherReturned,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan discount cells: %w", wrapScanError(err))
+		}
+		cells = append(cells, c)
+	}
+
+	return cells, rows.Err()
+}
+
+func (r *ReportRepository) OverlapCustomers(ctx context.Context, companyA, companyB string) ([]domain.OverlapCustomer, error) {
+	exec := r.getter.DefaultTrOrDB(ctx, r.pool)
+
+	rows, err := exec.Query(ctx, overlapCustomersSQL, companyA, companyB)
+	if err != nil {
+		return nil, fmt.Errorf("get overlap customers: %w", wrapScanError(err))
+	}
+	defer rows.Close()
+
+	var customers []domain.OverlapCustomer
+	for rows.Next() {
+		var (
+			c                      domain.OverlapCustomer
+			lastVisitA, lastVisitB pgtype.Timestamptz
+		)
+		err := rows.Scan(
+			&c.UserID,
+			&c.FullName,
+			&c.Phone,
+			&c.TurnoverA,
+			&c.TurnoverB,
+			&c.VisitsA,
+			&c.VisitsB,
+			&lastVisitA,
+			&lastVisitB,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan overlap customers: %w", wrapScanError(err))
+		}
+		c.LastVisitA = lastVisitA.Time
+		c.LastVisitB = lastVisitB.Time
+		customers = append(customers, c)
+	}
+
+	return customers, rows.Err()
+}
+
+func (r *ReportRepository) SwitchCandidates(ctx context.Context, companyA, companyB string) ([]domain.SwitchCandidate, error) {
+	exec := r.getter.DefaultTrOrDB(ctx, r.pool)
+
+	rows, err := exec.Query(ctx, switchCandidatesSQL, companyA, companyB)
+	if err != nil {
+		return nil, fmt.Errorf("get switch candidates: %w", wrapScanError(err))
+	}
+	defer rows.Close()
+
+	var candidates []domain.SwitchCandidate
+	for rows.Next() {
+		var (
+			c                                    domain.SwitchCandidate
+			prevA, prevB, lastVisitA, lastVisitB pgtype.Timestamptz
+		)
+		err := rows.Scan(&c.UserID, &c.FullName, &c.Phone, &prevA, &prevB, &lastVisitA, &lastVisitB)
+		if err != nil {
+			return nil, fmt.Errorf("scan switch candidates: %w", wrapScanError(err))
+		}
+		c.PrevLastVisitA = prevA.Time
+		c.PrevLastVisitB = prevB.Time
+		c.LastVisitA = lastVisitA.Time
+		c.LastVisitB = lastVisitB.Time
+		candidates = append(candidates, c)
+	}
+
+	return candidates, rows.Err()
+}
+
+func (r *ReportRepository) HeatmapCells(
+	ctx context.Context,
+	location string,
+	from, to domain.Date,
+	zone string,
+) ([]domain.HeatmapCell, error) {
+	exec := r.getter.DefaultTrOrDB(ctx, r.pool)
+
+	rows, err := exec.Query(ctx, heatmapCellsSQL, location, from, to, zone)
+	if err != nil {
+		return nil, fmt.Errorf("get heatmap cells: %w", wrapScanError(err))
+	}
+	defer rows.Close()
+
+	var cells []domain.HeatmapCell
+	for rows.Next() {
+		var c domain.HeatmapCell
+		if err := rows.Scan(&c.Weekday, &c.Hour, &c.Payments, &c.Revenue); err != nil {
+			return nil, fmt.Errorf("scan heatmap cells: %w", wrapScanError(err))
+		}
+		cells = append(cells, c)
+	}
+
+	return cells, rows.Err()
+}
+
+func (r *ReportRepository) StaffActivity(
+	ctx context.Context,
+	location string,
+	from, to domain.Date,
+	zone, groupBy string,
+) ([]domain.StaffActivity, error) {
+	exec := r.getter.DefaultTrOrDB(ctx, r.pool)
+
+	rows, err := exec.Query(ctx, staffActivitySQL, location, from, to, zone, groupBy)
+	if err != nil {
+		return nil, fmt.Errorf("get staff activity: %w", wrapScanError(err))
+	}
+	defer rows.Close()
+
+	var activity []domain.StaffActivity
+	for rows.Next() {
+		var a domain.StaffActivity
+		err := rows.Scan(
+			&a.Period,
+			&a.StaffID,
+			&a.StaffName,
+			&a.Payments,
+			&a.Revenue,
+			&a.Discounts,
+			&a.Refunds,
+			&a.RefundAmount,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan staff activity: %w", wrapScanError(err))
+		}
+		activity = append(activity, a)
+	}
+
+	return activity, rows.Err()
+}
@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ibookerke/choco_parser_go/internal/domain"
+	trmpgx "github.com/ibookerke/choco_parser_go/internal/pkg/pgx"
+	"github.com/ibookerke/choco_parser_go/internal/pkg/trm"
+)
+
+type RevenueRepository struct {
+	pool   *pgxpool.Pool
+	getter *trmpgx.CtxGetter
+	trm    trm.Manager
+}
+
+func NewRevenueRepository(
+	pool *pgxpool.Pool,
+	getter *trmpgx.CtxGetter,
+	trm trm.Manager,
+) *RevenueRepository {
+	return &RevenueRepository{
+		pool:   pool,
+		getter: getter,
+		trm:    trm,
+	}
+}
+
+const (
+	// netAmountSQL is the net revenue of a payments row, see domain.Payment.NetAmount. Every revenue
+	// figure follows it: a payment counts its amount less the refunds matched to it on the payment's
+	// day, a refund matched to no payment is subtracted on its own day, a matched one counts nowhere else.
+	netAmountSQL = `CASE
+		WHEN type = 'pay' THEN amount - refunded_amount
+		WHEN refund_of IS NULL THEN -ABS(amount)
+		ELSE 0
+	END`
+
+	dailyRevenueDeleteSQL = `DELETE FROM daily_revenue WHERE day >= $1`
+
+	// attributed payments are summed per location_id under the title of its main terminal,
+	// the rest per partner and location title
+	dailyRevenueInsertSQL = `INSERT INTO daily_revenue
+		(day, location_id, location_partner_id, location_title, gross_amount, discount_amount, refund_amount,
+		net_amount, payments_count, refunds_count)
+	SELECT
+		p.day,
+		p.location_id,
+		(array_agg(p.location_partner_id))[1],
+		COALESCE(MAX(l.location_name), (array_agg(p.location_title ORDER BY p.created_at DESC))[1], ''),
+		COALESCE(SUM(p.amount) FILTER (WHERE p.type = 'pay'), 0),
+		COALESCE(SUM(p.discount_amount) FILTER (WHERE p.type = 'pay'), 0),
+		COALESCE(SUM(p.amount) FILTER (WHERE p.type = 'pay'), 0) - COALESCE(SUM(p.net_amount), 0),
+		COALESCE(SUM(p.net_amount), 0),
+		COUNT(*) FILTER (WHERE p.type = 'pay'),
+		COUNT(*) FILTER (WHERE p.type = 'refund')
+	FROM (
+		SELECT *, (created_at AT TIME ZONE $2)::DATE AS day, ` + netAmountSQL + ` AS net_amount
+		FROM payments
+		WHERE created_at >= $1::DATE::TIMESTAMP AT TIME ZONE $2
+	) p
+	LEFT JOIN (
+		SELECT DISTINCT ON (location_id) location_id, NULLIF(location_name, '') AS location_name
+		FROM branches
+		WHERE location_id IS NOT NULL
+		ORDER BY location_id, (type_name = 'main') DESC, id
+	) l ON l.location_id = p.location_id
+	GROUP BY
+		p.day,
+		p.location_id,
+		CASE WHEN p.location_id IS NULL THEN p.location_partner_id END,
+		CASE WHEN p.location_id IS NULL THEN COALESCE(p.location_title, '') END`
+
+	revenueSQL = `SELECT
+		date_trunc($3, r.day)::DATE AS period,
+		COALESCE(r.location_id::TEXT, ''),
+		COALESCE(r.location_partner_id::TEXT, ''),
+		COALESCE(p.partner_name, ''),
+		r.location_title,
+		SUM(r.payments_count),
+		SUM(r.refunds_count),
+		SUM(r.gross_amount),
+		SUM(r.discount_amount),
+		SUM(r.refund_amount),
+		SUM(r.net_amount)
+	FROM daily_revenue r
+	LEFT JOIN (
+		SELECT DISTINCT ON (partner_id) partner_id, partner_name
+		FROM branches
+		ORDER BY partner_id, id
+	) p ON p.partner_id = r.location_partner_id
+	WHERE r.day BETWEEN $1 AND $2
+	GROUP BY 1, 2, 3, 4, 5
+	ORDER BY 1, 4, 5`
+
+	locationDailyRevenueSQL = `SELECT day, location_title, SUM(net_amount)
+	FROM daily_revenue
+	WHERE day BETWEEN $2 AND $3
+		AND ($1 = '' OR location_id::TEXT = $1 OR location_title ILIKE '%' || $1 || '%')
+	GROUP BY day, location_id, location_title`
+)
+
+// RefreshDailyRevenue should run inside a transaction so readers never see the days half refreshed.
+func (r *RevenueRepository) RefreshDailyRevenue(ctx context.Context, from domain.Date, zone string) error {
+	exec := r.getter.DefaultTrOrDB(ctx, r.pool)
+
+	if _, err := exec.Exec(ctx, dailyRevenueDeleteSQL, from); err != nil {
+		return fmt.Errorf("delete daily revenue: %w", wrapScanError(err))
+	}
+
+	if _, err := exec.Exec(ctx, dailyRevenueInsertSQL, from, zone); err != nil {
+		return fmt.Errorf("insert daily revenue: %w", wrapScanError(err))
+	}
+
+	return nil
+}
+
+func (r *RevenueRepository) Revenue(ctx context.Context, from, to domain.Date, groupBy string) ([]domain.RevenueRow, error) {
+	exec := r.getter.DefaultTrOrDB(ctx, r.pool)
+
+	rows, err := exec.Query(ctx, revenueSQL, from, to, groupBy)
+	if err != nil {
+		return nil, fmt.Errorf("get revenue: %w", wrapScanError(err))
+	}
+	defer rows.Close()
+
+	var revenue []domain.RevenueRow
+	for rows.Next() {
+		var row domain.RevenueRow
+		err := rows.Scan(
+			&row.Period,
+			&row.LocationID,
+			&row.PartnerID,
+			&row.PartnerName,
+			&row.Location,
+			&row.Payments,
+			&row.Refunds,
+			&row.Gross,
+			&row.Discounts,
+			&row.RefundAmount,
+			&row.Net,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan revenue: %w", wrapScanError(err))
+		}
+		revenue = append(revenue, row)
+	}
+
+	return revenue, rows.Err()
+}
+
+func (r *RevenueRepository) LocationDailyRevenue(
+	ctx context.Context,
+	location string,
+	from, to domain.Date,
+) ([]domain.LocationRevenue, error) {
+	exec := r.getter.DefaultTrOrDB(ctx, r.pool)
+
+	rows, err := exec.Query(ctx, locationDailyRevenueSQL, location, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("get location daily revenue: %w", wrapScanError(err))
+	}
+	defer rows.Close()
+
+	var revenue []domain.LocationRevenue
+	for rows.Next() {
+		var lr domain.LocationRevenue
+		if err := rows.Scan(&lr.Day, &lr.Location, &lr.Net); err != nil {
+			return nil, fmt.Errorf("scan location daily revenue: %w", wrapScanError(err))
+		}
+		revenue = append(revenue, lr)
+	}
+
+	return revenue, rows.Err()
+}
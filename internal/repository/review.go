@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ibookerke/choco_parser_go/internal/domain"
+	trmpgx "github.com/ibookerke/choco_parser_go/internal/pkg/pgx"
+	"github.com/ibookerke/choco_parser_go/internal/pkg/trm"
+)
+
+type ReviewRepository struct {
+	pool   *pgxpool.Pool
+	getter *trmpgx.CtxGetter
+	trm    trm.Manager
+}
+
+func NewReviewRepository(
+	pool *pgxpool.Pool,
+	getter *trmpgx.CtxGetter,
+	trm trm.Manager,
+) *ReviewRepository {
+	return &ReviewRepository{
+		pool:   pool,
+		getter: getter,
+		trm:    trm,
+	}
+}
+
+const (
+	reviewStoreSQL = `INSERT INTO payment_reviews
+    (payment_id, user_id, rating, comment, created_at, raw)
+    VALUES ($1, $2, $3, $4, $5, $6)
+    ON CONFLICT (payment_id) DO UPDATE
+    SET user_id = EXCLUDED.user_id, rating = EXCLUDED.rating, comment = EXCLUDED.comment,
+        created_at = EXCLUDED.created_at, raw = EXCLUDED.raw`
+
+	reviewFindByUserIdSQL = `SELECT
+		payment_id, COALESCE(user_id, 0), COALESCE(rating, 0), COALESCE(comment, ''), created_at, raw
+	FROM payment_reviews
+	WHERE user_id = $1
+	ORDER BY created_at DESC NULLS LAST
+	LIMIT $2`
+)
+
+func (r *ReviewRepository) Store(ctx context.Context, review *domain.Review) error {
+	exec := r.getter.DefaultTrOrDB(ctx, r.pool)
+	_, err := exec.Exec(ctx, reviewStoreSQL,
+		review.PaymentID,
+		nullInt64(review.UserID),
+		review.Rating,
+		nullString(review.Comment),
+		nullTime(review.CreatedAt),
+		review.Raw,
+	)
+	if err != nil {
+		return fmt.Errorf("store review: %w", wrapScanError(err))
+	}
+	return nil
+}
+
+func (r *ReviewRepository) FindByUserID(ctx context.Context, userID int64, limit int) ([]domain.Review, error) {
+	exec := r.getter.DefaultTrOrDB(ctx, r.pool)
+
+	rows, err := exec.Query(ctx, reviewFindByUserIdSQL, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("find reviews by user id: %w", wrapScanError(err))
+	}
+	defer rows.Close()
+
+	var reviews []domain.Review
+	for rows.Next() {
+		var (
+			review    domain.Review
+			createdAt pgtype.Timestamptz
+		)
+		err := rows.Scan(
+			&review.PaymentID,
+			&review.UserID,
+			&review.Rating,
+			&review.Comment,
+			&createdAt,
+			&review.Raw,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan reviews by user id: %w", wrapScanError(err))
+		}
+		review.CreatedAt = createdAt.Time
+		reviews = append(reviews, review)
+	}
+
+	return reviews, rows.Err()
+}
@@ -0,0 +1,306 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ibookerke/choco_parser_go/internal/domain"
+	trmpgx "github.com/ibookerke/choco_parser_go/internal/pkg/pgx"
+	"github.com/ibookerke/choco_parser_go/internal/pkg/trm"
+)
+
+type SavedSegmentRepository struct {
+	pool   *pgxpool.Pool
+	getter *trmpgx.CtxGetter
+	trm    trm.Manager
+}
+
+func NewSavedSegmentRepository(
+	pool *pgxpool.Pool,
+	getter *trmpgx.CtxGetter,
+	trm trm.Manager,
+) *SavedSegmentRepository {
+	return &SavedSegmentRepository{
+		pool:   pool,
+		getter: getter,
+		trm:    trm,
+	}
+}
+
+const (
+	savedSegmentColumns = `id, name, filter, created_at, updated_at, evaluated_at, members_count`
+
+	// savedSegmentSaveSQL forgets the evaluation when the filter changes,
+	// the members of the old filter are no baseline for the new one.
+	savedSegmentSaveSQL = `INSERT INTO segments (name, filter, created_at, updated_at)
+	VALUES ($1, $2, $3, $3)
+	ON CONFLICT (name) DO UPDATE
+	SET filter = EXCLUDED.filter,
+		updated_at = EXCLUDED.updated_at,
+		evaluated_at = CASE WHEN segments.filter = EXCLUDED.filter THEN segments.evaluated_at END,
+		members_count = CASE WHEN segments.filter = EXCLUDED.filter THEN segments.members_count ELSE 0 END
+	RETURNING ` + savedSegmentColumns
+
+	savedSegmentFindByNameSQL = `SELECT ` + savedSegmentColumns + ` FROM segments WHERE name = $1`
+
+	savedSegmentListSQL = `SELECT ` + savedSegmentColumns + ` FROM segments ORDER BY name`
+
+	// the %s is a condition rendered by domain.SegmentCondition, its literals are bound as arguments
+	savedSegmentMatchSQL = `SELECT DISTINCT p.user_id
+	FROM customer_profiles p
+	LEFT JOIN customer_company_stats s ON s.user_id = p.user_id
+	WHERE %s
+	ORDER BY p.user_id`
+
+	savedSegmentMatchPhonesSQL = `SELECT DISTINCT p.phone_normalized
+	FROM customer_profiles p
+	LEFT JOIN customer_company_stats s ON s.user_id = p.user_id
+	WHERE p.phone_normalized <> '' AND %s
+	ORDER BY p.phone_normalized`
+
+	savedSegmentMembersSQL = `SELECT user_id FROM segment_members WHERE segment_id = $1`
+
+	savedSegmentInsertEventSQL = `INSERT INTO segment_member_events (segment_id, user_id, event, happened_at)
+	VALUES ($1, $2, $3, $4)`
+
+	savedSegmentMemberChangesSQL = `SELECT e.segment_id, e.user_id, e.event, e.happened_at,
+		COALESCE(p.full_name, ''), COALESCE(p.phone, '')
+	FROM segment_member_events e
+	LEFT JOIN customer_profiles p ON p.user_id = e.user_id
+	WHERE e.segment_id = $1
+		AND ($2 = '' OR e.event = $2)
+		AND e.happened_at >= $3::DATE::TIMESTAMP AT TIME ZONE $5
+		AND e.happened_at < ($4::DATE + 1)::TIMESTAMP AT TIME ZONE $5
+	ORDER BY e.happened_at, e.event, e.user_id`
+
+	savedSegmentChangePhonesSQL = `SELECT DISTINCT p.phone_normalized
+	FROM segment_member_events e
+	JOIN customer_profiles p ON p.user_id = e.user_id
+	WHERE e.segment_id = $1 AND e.event = $2 AND e.happened_at = $3 AND p.phone_normalized <> ''
+	ORDER BY p.phone_normalized`
+
+	savedSegmentDeleteMembersSQL = `DELETE FROM segment_members WHERE segment_id = $1`
+
+	savedSegmentInsertMemberSQL = `INSERT INTO segment_members (segment_id, user_id) VALUES ($1, $2)`
+
+	savedSegmentEvaluatedSQL = `UPDATE segments SET evaluated_at = $2, members_count = $3 WHERE id = $1`
+)
+
+func (r *SavedSegmentRepository) Save(ctx context.Context, name, filter string, now time.Time) (*domain.SavedSegment, error) {
+	exec := r.getter.DefaultTrOrDB(ctx, r.pool)
+
+	segment, err := scanSavedSegment(exec.QueryRow(ctx, savedSegmentSaveSQL, name, filter, now))
+	if err != nil {
+		return nil, fmt.Errorf("save segment: %w", wrapScanError(err))
+	}
+
+	if segment.EvaluatedAt.IsZero() {
+		if _, err := exec.Exec(ctx, savedSegmentDeleteMembersSQL, segment.ID); err != nil {
+			return nil, fmt.Errorf("delete segment members: %w", wrapScanError(err))
+		}
+	}
+
+	return segment, nil
+}
+
+func (r *SavedSegmentRepository) FindByName(ctx context.Context, name string) (*domain.SavedSegment, error) {
+	exec := r.getter.DefaultTrOrDB(ctx, r.pool)
+
+	segment, err := scanSavedSegment(exec.QueryRow(ctx, savedSegmentFindByNameSQL, name))
+	if err != nil {
+		return nil, fmt.Errorf("find segment by name: %w", wrapScanError(err))
+	}
+
+	return segment, nil
+}
+
+func (r *SavedSegmentRepository) List(ctx context.Context) ([]domain.SavedSegment, error) {
+	exec := r.getter.DefaultTrOrDB(ctx, r.pool)
+
+	rows, err := exec.Query(ctx, savedSegmentListSQL)
+	if err != nil {
+		return nil, fmt.Errorf("list segments: %w", wrapScanError(err))
+	}
+	defer rows.Close()
+
+	var segments []domain.SavedSegment
+	for rows.Next() {
+		segment, err := scanSavedSegment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan segment: %w", wrapScanError(err))
+		}
+		segments = append(segments, *segment)
+	}
+
+	return segments, rows.Err()
+}
+
+func (r *SavedSegmentRepository) Match(ctx context.Context, cond domain.SegmentCondition, now time.Time) ([]int64, error) {
+	exec := r.getter.DefaultTrOrDB(ctx, r.pool)
+
+	where, args := cond.SQL(now)
+	rows, err := exec.Query(ctx, fmt.Sprintf(savedSegmentMatchSQL, where), args...)
+	if err != nil {
+		return nil, fmt.Errorf("match segment: %w", wrapScanError(err))
+	}
+	defer rows.Close()
+
+	var userIDs []int64
+	for rows.Next() {
+		var userID int64
+		if err := rows.Scan(&userID); err != nil {
+			return nil, fmt.Errorf("scan segment member: %w", wrapScanError(err))
+		}
+		userIDs = append(userIDs, userID)
+	}
+
+	return userIDs, rows.Err()
+}
+
+func (r *SavedSegmentRepository) MatchPhones(ctx context.Context, cond domain.SegmentCondition, now time.Time) ([]string, error) {
+	exec := r.getter.DefaultTrOrDB(ctx, r.pool)
+
+	where, args := cond.SQL(now)
+	rows, err := exec.Query(ctx, fmt.Sprintf(savedSegmentMatchPhonesSQL, where), args...)
+	if err != nil {
+		return nil, fmt.Errorf("match segment phones: %w", wrapScanError(err))
+	}
+	defer rows.Close()
+
+	var phones []string
+	for rows.Next() {
+		var phone string
+		if err := rows.Scan(&phone); err != nil {
+			return nil, fmt.Errorf("scan segment phone: %w", wrapScanError(err))
+		}
+		phones = append(phones, phone)
+	}
+
+	return phones, rows.Err()
+}
+
+func (r *SavedSegmentRepository) Members(ctx context.Context, segmentID int64) ([]int64, error) {
+	exec := r.getter.DefaultTrOrDB(ctx, r.pool)
+
+	rows, err := exec.Query(ctx, savedSegmentMembersSQL, segmentID)
+	if err != nil {
+		return nil, fmt.Errorf("get segment members: %w", wrapScanError(err))
+	}
+	defer rows.Close()
+
+	var userIDs []int64
+	for rows.Next() {
+		var userID int64
+		if err := rows.Scan(&userID); err != nil {
+			return nil, fmt.Errorf("scan segment member: %w", wrapScanError(err))
+		}
+		userIDs = append(userIDs, userID)
+	}
+
+	return userIDs, rows.Err()
+}
+
+func (r *SavedSegmentRepository) RecordMemberEvents(ctx context.Context, events []domain.SegmentMemberEvent) error {
+	exec := r.getter.DefaultTrOrDB(ctx, r.pool)
+
+	for _, e := range events {
+		if _, err := exec.Exec(ctx, savedSegmentInsertEventSQL, e.SegmentID, e.UserID, e.Event, e.At); err != nil {
+			return fmt.Errorf("insert segment member event: %w", wrapScanError(err))
+		}
+	}
+
+	return nil
+}
+
+func (r *SavedSegmentRepository) MemberChanges(
+	ctx context.Context,
+	segmentID int64,
+	event string,
+	from, to domain.Date,
+	zone string,
+) ([]domain.SegmentMemberChange, error) {
+	exec := r.getter.DefaultTrOrDB(ctx, r.pool)
+
+	rows, err := exec.Query(ctx, savedSegmentMemberChangesSQL, segmentID, event, from, to, zone)
+	if err != nil {
+		return nil, fmt.Errorf("get segment member changes: %w", wrapScanError(err))
+	}
+	defer rows.Close()
+
+	var changes []domain.SegmentMemberChange
+	for rows.Next() {
+		var c domain.SegmentMemberChange
+		if err := rows.Scan(&c.SegmentID, &c.UserID, &c.Event, &c.At, &c.FullName, &c.Phone); err != nil {
+			return nil, fmt.Errorf("scan segment member change: %w", wrapScanError(err))
+		}
+		changes = append(changes, c)
+	}
+
+	return changes, rows.Err()
+}
+
+func (r *SavedSegmentRepository) ChangePhones(ctx context.Context, segmentID int64, event string, at time.Time) ([]string, error) {
+	exec := r.getter.DefaultTrOrDB(ctx, r.pool)
+
+	rows, err := exec.Query(ctx, savedSegmentChangePhonesSQL, segmentID, event, at)
+	if err != nil {
+		return nil, fmt.Errorf("get segment change phones: %w", wrapScanError(err))
+	}
+	defer rows.Close()
+
+	var phones []string
+	for rows.Next() {
+		var phone string
+		if err := rows.Scan(&phone); err != nil {
+			return nil, fmt.Errorf("scan segment change phone: %w", wrapScanError(err))
+		}
+		phones = append(phones, phone)
+	}
+
+	return phones, rows.Err()
+}
+
+// ReplaceMembers should run inside a transaction so the members always match the segment's count.
+func (r *SavedSegmentRepository) ReplaceMembers(
+	ctx context.Context,
+	segmentID int64,
+	userIDs []int64,
+	evaluatedAt time.Time,
+) error {
+	exec := r.getter.DefaultTrOrDB(ctx, r.pool)
+
+	if _, err := exec.Exec(ctx, savedSegmentDeleteMembersSQL, segmentID); err != nil {
+		return fmt.Errorf("delete segment members: %w", wrapScanError(err))
+	}
+
+	for _, userID := range userIDs {
+		if _, err := exec.Exec(ctx, savedSegmentInsertMemberSQL, segmentID, userID); err != nil {
+			return fmt.Errorf("insert segment member: %w", wrapScanError(err))
+		}
+	}
+
+	if _, err := exec.Exec(ctx, savedSegmentEvaluatedSQL, segmentID, evaluatedAt, len(userIDs)); err != nil {
+		return fmt.Errorf("update segment evaluation: %w", wrapScanError(err))
+	}
+
+	return nil
+}
+
+func scanSavedSegment(row pgx.Row) (*domain.SavedSegment, error) {
+	var (
+		s           domain.SavedSegment
+		evaluatedAt pgtype.Timestamptz
+	)
+	err := row.Scan(&s.ID, &s.Name, &s.Filter, &s.CreatedAt, &s.UpdatedAt, &evaluatedAt, &s.Members)
+	if err != nil {
+		return nil, err
+	}
+	s.EvaluatedAt = evaluatedAt.Time
+
+	return &s, nil
+}
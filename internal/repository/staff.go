@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	trmpgx "github.com/ibookerke/choco_parser_go/internal/pkg/pgx"
+	"github.com/ibookerke/choco_parser_go/internal/pkg/trm"
+)
+
+type StaffRepository struct {
+	pool   *pgxpool.Pool
+	getter *trmpgx.CtxGetter
+	trm    trm.Manager
+}
+
+func NewStaffRepository(
+	pool *pgxpool.Pool,
+	getter *trmpgx.CtxGetter,
+	trm trm.Manager,
+) *StaffRepository {
+	return &StaffRepository{
+		pool:   pool,
+		getter: getter,
+		trm:    trm,
+	}
+}
+
+const (
+	staffTouchSQL = `INSERT INTO staff (id, first_seen_at, last_seen_at)
+	VALUES ($1, $2, $2)
+	ON CONFLICT (id) DO UPDATE
+	SET first_seen_at = LEAST(staff.first_seen_at, EXCLUDED.first_seen_at),
+		last_seen_at = GREATEST(staff.last_seen_at, EXCLUDED.last_seen_at)`
+)
+
+func (r *StaffRepository) Touch(ctx context.Context, id int64, seenAt time.Time) error {
+	exec := r.getter.DefaultTrOrDB(ctx, r.pool)
+	if _, err := exec.Exec(ctx, staffTouchSQL, id, seenAt); err != nil {
+		return fmt.Errorf("touch staff: %w", wrapScanError(err))
+	}
+
+	return nil
+}
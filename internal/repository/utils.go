@@ -3,6 +3,7 @@ package repository
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
@@ -31,3 +32,30 @@ func wrapScanError(err error) error {
 
 	return fmt.Errorf("%w: %s", errStorage, err)
 }
+
+// nullTime maps the zero time to NULL.
+func nullTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	return &t
+}
+
+// nullString maps the empty string to NULL.
+func nullString(s string) *string {
+	if s == "" {
+		return nil
+	}
+
+	return &s
+}
+
+// nullInt64 maps zero to NULL.
+func nullInt64(v int64) *int64 {
+	if v == 0 {
+		return nil
+	}
+
+	return &v
+}
@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ibookerke/choco_parser_go/internal/config"
+	"github.com/ibookerke/choco_parser_go/internal/domain"
+	"github.com/ibookerke/choco_parser_go/internal/pkg/chocotime"
+	"github.com/ibookerke/choco_parser_go/internal/pkg/notify"
+)
+
+// noPaymentsLookback is how far back a no_payments rule looks on its first evaluation.
+const noPaymentsLookback = 24 * time.Hour
+
+type AlertService struct {
+	alertRepo   domain.AlertRepository
+	revenueRepo domain.RevenueRepository
+	notifier    notify.Notifier
+	rules       []domain.AlertRule
+}
+
+// NewAlertService validates the configured rules and that their names are unique,
+// a broken rules file fails at startup.
+func NewAlertService(
+	alertRepo domain.AlertRepository,
+	revenueRepo domain.RevenueRepository,
+	notifier notify.Notifier,
+	cfg config.Alerts,
+) (*AlertService, error) {
+	rules := make([]domain.AlertRule, 0, len(cfg.Rules))
+	names := make(map[string]bool, len(cfg.Rules))
+	for _, ruleCfg := range cfg.Rules {
+		rule, err := alertRule(ruleCfg)
+		if err != nil {
+			return nil, err
+		}
+		// alerts and evaluations are kept per rule name
+		if names[rule.Name] {
+			return nil, fmt.Errorf("alert rule %q is defined more than once", rule.Name)
+		}
+		names[rule.Name] = true
+		rules = append(rules, rule)
+	}
+
+	return &AlertService{
+		alertRepo:   alertRepo,
+		revenueRepo: revenueRepo,
+		notifier:    notifier,
+		rules:       rules,
+	}, nil
+}
+
+// Evaluate checks every configured rule at now, stores the alerts that fired and sends the ones
+// not sent yet, including those a previous evaluation failed to send.
+// Revenue drops are checked for the day before now, the last one the sync has complete,
+// quiet gaps are scanned since the previous evaluation of their rule.
+// A failed send doesn't stop the evaluation, the send errors are returned together at the end.
+func (s *AlertService) Evaluate(ctx context.Context, now time.Time) error {
+	now = now.In(chocotime.Location)
+
+	var sendErrs []error
+	for _, rule := range s.rules {
+		var alerts []domain.Alert
+		switch rule.Kind {
+		case domain.AlertRevenueDrop:
+			day := domain.DateOf(now.AddDate(0, 0, -1))
+			from := domain.DateOf(now.AddDate(0, 0, -1-7*rule.Weeks))
+
+			revenue, err := s.revenueRepo.LocationDailyRevenue(ctx, rule.Location, from, day)
+			if err != nil {
+				return fmt.Errorf("failed to get daily revenue for rule %q: %w", rule.Name, err)
+			}
+			alerts = domain.RevenueDropAlerts(rule, day, revenue, now)
+		case domain.AlertNoPayments:
+			since, err := s.alertRepo.EvaluatedAt(ctx, rule.Name)
+			if err != nil {
+				return fmt.Errorf("failed to get the last evaluation of rule %q: %w", rule.Name, err)
+			}
+			if since.IsZero() {
+				since = now.Add(-noPaymentsLookback)
+			}
+
+			payments, err := s.alertRepo.PaymentTimes(ctx, rule.Location, since)
+			if err != nil {
+				return fmt.Errorf("failed to get payment times for rule %q: %w", rule.Name, err)
+			}
+			alerts = domain.NoPaymentsAlerts(rule, since, now, payments)
+		}
+
+		for i := range alerts {
+			if _, err := s.alertRepo.Store(ctx, &alerts[i]); err != nil {
+				return fmt.Errorf("failed to store alert of rule %q: %w", rule.Name, err)
+			}
+		}
+
+		if err := s.alertRepo.SetEvaluatedAt(ctx, rule.Name, now); err != nil {
+			return fmt.Errorf("failed to save the evaluation of rule %q: %w", rule.Name, err)
+		}
+
+		if err := s.send(ctx, rule.Name); err != nil {
+			sendErrs = append(sendErrs, err)
+		}
+	}
+
+	return errors.Join(sendErrs...)
+}
+
+// send notifies about the stored alerts of the rule that were not sent yet,
+// it keeps going after a failed send so one broken alert doesn't hold back the others.
+func (s *AlertService) send(ctx context.Context, rule string) error {
+	alerts, err := s.alertRepo.Unnotified(ctx, rule)
+	if err != nil {
+		return fmt.Errorf("failed to get unsent alerts of rule %q: %w", rule, err)
+	}
+
+	var failed int
+	var lastErr error
+	for _, alert := range alerts {
+		err := s.notifier.Notify(ctx, notify.Message{
+			Subject: fmt.Sprintf("alert %s: %s", alert.Rule, alert.Location),
+			Body:    alert.Message,
+			SentAt:  alert.TriggeredAt,
+		})
+		if err != nil {
+			failed++
+			lastErr = err
+			continue
+		}
+
+		if err := s.alertRepo.MarkNotified(ctx, alert.ID, time.Now()); err != nil {
+			return fmt.Errorf("failed to mark alert %d of rule %q sent: %w", alert.ID, rule, err)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to send %d of %d alerts of rule %q: %w", failed, len(alerts), rule, lastErr)
+	}
+
+	return nil
+}
+
+// alertRule validates a rule of the rules file.
+func alertRule(cfg config.AlertRule) (domain.AlertRule, error) {
+	rule := domain.AlertRule{
+		Name:     cfg.Name,
+		Kind:     cfg.Kind,
+		Location: cfg.Location,
+		Drop:     cfg.Drop,
+		Weeks:    cfg.Weeks,
+	}
+	if rule.Name == "" {
+		return rule, fmt.Errorf("alert rule of kind %q has no name", cfg.Kind)
+	}
+
+	switch rule.Kind {
+	case domain.AlertRevenueDrop:
+		if rule.Drop <= 0 || rule.Drop > 1 {
+			return rule, fmt.Errorf("alert rule %q: drop must be within (0, 1], got %v", rule.Name, rule.Drop)
+		}
+		if rule.Weeks < 1 {
+			rule.Weeks = 4
+		}
+	case domain.AlertNoPayments:
+		var err error
+		if rule.Window, err = time.ParseDuration(cfg.Window); err != nil || rule.Window <= 0 {
+			return rule, fmt.Errorf("alert rule %q: invalid window %q", rule.Name, cfg.Window)
+		}
+		if rule.Opens, err = clockOffset(cfg.Opens); err != nil {
+			return rule, fmt.Errorf("alert rule %q: invalid opens: %w", rule.Name, err)
+		}
+		if rule.Closes, err = clockOffset(cfg.Closes); err != nil {
+			return rule, fmt.Errorf("alert rule %q: invalid closes: %w", rule.Name, err)
+		}
+		if rule.Closes <= rule.Opens {
+			return rule, fmt.Errorf("alert rule %q: closes must be after opens", rule.Name)
+		}
+	default:
+		return rule, fmt.Errorf("alert rule %q: unknown kind %q", rule.Name, rule.Kind)
+	}
+
+	return rule, nil
+}
+
+// clockOffset turns "HH:MM" into the offset from midnight, "24:00" is the end of the day.
+func clockOffset(s string) (time.Duration, error) {
+	if s == "24:00" {
+		return 24 * time.Hour, nil
+	}
+
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("expected HH:MM, got %q", s)
+	}
+
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
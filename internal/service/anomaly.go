@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ibookerke/choco_parser_go/internal/domain"
+	"github.com/ibookerke/choco_parser_go/internal/pkg/chocotime"
+)
+
+type AnomalyService struct {
+	paymentRepo domain.PaymentRepository
+}
+
+func NewAnomalyService(paymentRepo domain.PaymentRepository) *AnomalyService {
+	return &AnomalyService{
+		paymentRepo: paymentRepo,
+	}
+}
+
+// Anomalies flags the customers and staff with unusual payments between from and to inclusive.
+// Maximum discounts are compared with the largest discount given at the location in the same range.
+func (s *AnomalyService) Anomalies(
+	ctx context.Context,
+	from, to domain.Date,
+	thresholds domain.AnomalyThresholds,
+) ([]domain.Anomaly, error) {
+	switch {
+	case thresholds.BurstWindow <= 0:
+		return nil, fmt.Errorf("burst window must be positive, got %s", thresholds.BurstWindow)
+	case thresholds.MinPayments < 1:
+		return nil, fmt.Errorf("min payments must be at least 1, got %d", thresholds.MinPayments)
+	case thresholds.RefundRatio <= 0 || thresholds.RefundRatio > 1:
+		return nil, fmt.Errorf("refund ratio must be within (0, 1], got %v", thresholds.RefundRatio)
+	case thresholds.MaxDiscountRepeats < 1:
+		return nil, fmt.Errorf("max discounts must be at least 1, got %d", thresholds.MaxDiscountRepeats)
+	case thresholds.BurstCount < 1:
+		return nil, fmt.Errorf("burst count must be at least 1, got %d", thresholds.BurstCount)
+	}
+
+	payments, err := s.paymentRepo.FindBetween(ctx, from.In(chocotime.Location), to.In(chocotime.Location).AddDate(0, 0, 1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find payments: %w", err)
+	}
+
+	return domain.DetectAnomalies(payments, thresholds), nil
+}
@@ -129,18 +129,9 @@ func (bs *BranchService) FetchBranches(ctx context.Context) (string, error) {
 }
 
 func (bs *BranchService) GetBranchTerminals(ctx context.Context, companyName string) (string, error) {
-	branches, err := bs.branchRepo.GetBranchesByCompanyName(ctx, companyName)
+	branches, err := companyBranchIds(ctx, bs.branchRepo, companyName)
 	if err != nil {
-		return "", fmt.Errorf("failed to get branches: %v", err)
-	}
-
-	if companyName == "malatang" {
-		extraBranches, err := bs.branchRepo.GetBranchesByCompanyName(ctx, "maratang")
-		if err != nil {
-			return "", fmt.Errorf("failed to get branches: %v", err)
-		}
-
-		branches = append(branches, extraBranches...)
+		return "", err
 	}
 
 	// implode slice of branch ids to a string by comma
@@ -151,3 +142,22 @@ func (bs *BranchService) GetBranchTerminals(ctx context.Context, companyName str
 
 	return strings.Join(branchIds[:], ","), nil
 }
+
+// companyBranchIds returns the terminals of the company, matched by the terminal name.
+func companyBranchIds(ctx context.Context, branchRepo domain.BranchRepository, companyName string) ([]domain.BranchId, error) {
+	branches, err := branchRepo.GetBranchesByCompanyName(ctx, companyName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get branches: %v", err)
+	}
+
+	if companyName == "malatang" {
+		extraBranches, err := branchRepo.GetBranchesByCompanyName(ctx, "maratang")
+		if err != nil {
+			return nil, fmt.Errorf("failed to get branches: %v", err)
+		}
+
+		branches = append(branches, extraBranches...)
+	}
+
+	return branches, nil
+}
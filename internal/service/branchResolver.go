@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ibookerke/choco_parser_go/internal/domain"
+)
+
+// mainTerminalType is the terminal type preferred when a location has several terminals.
+const mainTerminalType = "main"
+
+// branchResolver attributes payments to one of the terminals their history was requested for.
+// The payment history only carries the location title and partner id, so a payment is matched
+// to the location with the same partner and title and to the main terminal of that location.
+type branchResolver struct {
+	byLocation map[locationKey]*domain.Branch
+	// byPartner holds the single location of partners that have exactly one location in the set
+	byPartner map[string]*domain.Branch
+}
+
+type locationKey struct {
+	partnerID string
+	title     string
+}
+
+func newBranchResolver(ctx context.Context, branchRepo domain.BranchRepository, terminals string) (*branchResolver, error) {
+	ids, err := domain.BranchIdsFromStr(terminals)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse terminals: %w", err)
+	}
+
+	branches, err := branchRepo.GetByIds(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get terminals: %w", err)
+	}
+
+	return indexBranches(branches), nil
+}
+
+// indexBranches builds the resolver over the given terminals.
+func indexBranches(branches []*domain.Branch) *branchResolver {
+	r := &branchResolver{
+		byLocation: make(map[locationKey]*domain.Branch),
+		byPartner:  make(map[string]*domain.Branch),
+	}
+
+	partnerLocations := make(map[string]map[string]struct{})
+	for _, b := range branches {
+		key := locationKey{partnerID: b.PartnerID, title: normalizeLocationTitle(b.LocationName)}
+		if current, ok := r.byLocation[key]; !ok || preferBranch(b, current) {
+			r.byLocation[key] = b
+		}
+
+		if partnerLocations[b.PartnerID] == nil {
+			partnerLocations[b.PartnerID] = make(map[string]struct{})
+		}
+		partnerLocations[b.PartnerID][b.LocationID] = struct{}{}
+	}
+
+	for key, b := range r.byLocation {
+		if len(partnerLocations[key.partnerID]) == 1 {
+			r.byPartner[key.partnerID] = b
+		}
+	}
+
+	return r
+}
+
+// resolve returns the branch the payment was made at or nil when it can't be told apart.
+func (r *branchResolver) resolve(partnerID, locationTitle string) *domain.Branch {
+	if b, ok := r.byLocation[locationKey{partnerID: partnerID, title: normalizeLocationTitle(locationTitle)}]; ok {
+		return b
+	}
+
+	return r.byPartner[partnerID]
+}
+
+func preferBranch(candidate, current *domain.Branch) bool {
+	candidateMain := candidate.TypeName == mainTerminalType
+	currentMain := current.TypeName == mainTerminalType
+	if candidateMain != currentMain {
+		return candidateMain
+	}
+
+	return candidate.ID < current.ID
+}
+
+func normalizeLocationTitle(title string) string {
+	return strings.ToLower(strings.Join(strings.Fields(title), " "))
+}
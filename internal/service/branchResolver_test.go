@@ -0,0 +1,55 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ibookerke/choco_parser_go/internal/domain"
+)
+
+func TestBranchResolver_resolve(t *testing.T) {
+	t.Parallel()
+
+	r := indexBranches([]*domain.Branch{
+		// one partner, one location with a main and a secondary terminal
+		{ID: 12, TypeName: "kiosk", LocationID: "10", LocationName: "Malatang  Dostyk", PartnerID: "1"},
+		{ID: 11, TypeName: mainTerminalType, LocationID: "10", LocationName: "malatang dostyk", PartnerID: "1"},
+		// another partner with two locations
+		{ID: 21, TypeName: "kiosk", LocationID: "20", LocationName: "Coffee Abaya", PartnerID: "2"},
+		{ID: 22, TypeName: "kiosk", LocationID: "20", LocationName: "Coffee Abaya", PartnerID: "2"},
+		{ID: 23, TypeName: mainTerminalType, LocationID: "30", LocationName: "Coffee Sairan", PartnerID: "2"},
+	})
+
+	tests := map[string]struct {
+		partnerID string
+		title     string
+		wantID    domain.BranchId
+	}{
+		"main_terminal":          {partnerID: "1", title: "Malatang Dostyk", wantID: 11},
+		"normalized_title":       {partnerID: "1", title: "  MALATANG\tdostyk ", wantID: 11},
+		"lowest_id_without_main": {partnerID: "2", title: "coffee abaya", wantID: 21},
+		"other_location":         {partnerID: "2", title: "Coffee Sairan", wantID: 23},
+		"partner_fallback":       {partnerID: "1", title: "Malatang Dostyk Mall", wantID: 11},
+		"ambiguous_title":        {partnerID: "2", title: "Coffee", wantID: 0},
+		"other_partner":          {partnerID: "1", title: "Coffee Abaya", wantID: 11},
+		"unknown_partner":        {partnerID: "3", title: "Malatang Dostyk", wantID: 0},
+		"no_match":               {partnerID: "", title: "", wantID: 0},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got := r.resolve(tt.partnerID, tt.title)
+			if tt.wantID == 0 {
+				assert.Nil(t, got)
+				return
+			}
+			if assert.NotNil(t, got) {
+				assert.Equal(t, tt.wantID, got.ID)
+			}
+		})
+	}
+}
@@ -9,22 +9,27 @@ import (
 
 	"github.com/ibookerke/choco_parser_go/internal/config"
 	"github.com/ibookerke/choco_parser_go/internal/domain"
+	"github.com/ibookerke/choco_parser_go/internal/pkg/chocotime"
+	"github.com/ibookerke/choco_parser_go/internal/pkg/phone"
 	"github.com/ibookerke/choco_parser_go/internal/pkg/trm"
 )
 
 type CompanyCustomersService struct {
 	companyCustomerRepo domain.CompanyCustomerRepository
+	privacyRepo         domain.PrivacyRepository
 	trm                 trm.Manager
 	cfg                 config.Choco
 }
 
 func NewCompanyCustomersService(
 	companyCustomerRepo domain.CompanyCustomerRepository,
+	privacyRepo domain.PrivacyRepository,
 	trm trm.Manager,
 	cfg config.Choco,
 ) *CompanyCustomersService {
 	return &CompanyCustomersService{
 		companyCustomerRepo: companyCustomerRepo,
+		privacyRepo:         privacyRepo,
 		trm:                 trm,
 		cfg:                 cfg,
 	}
@@ -40,13 +45,13 @@ type CustomersResponse struct {
 	Data []struct {
 		ID         int64 `json:"id"`
 		Attributes struct {
-			UserID        int64   `json:"user_id"`
-			Phone         string  `json:"phone"`
-			Turnover      float64 `json:"turnover"`
-			FullName      string  `json:"full_name"`
-			LastVisitDate string  `json:"last_visit_date"`
-			VisitsCount   int64   `json:"visits_count"`
-			AverageBill   float64 `json:"average_bill"`
+			UserID        int64        `json:"user_id"`
+			Phone         string       `json:"phone"`
+			Turnover      domain.Money `json:"turnover"`
+			FullName      string       `json:"full_name"`
+			LastVisitDate string       `json:"last_visit_date"`
+			VisitsCount   int64        `json:"visits_count"`
+			AverageBill   domain.Money `json:"average_bill"`
 		} `json:"attributes"`
 	} `json:"data"`
 }
@@ -55,13 +60,16 @@ func (s *CompanyCustomersService) FetchCompanyCustomers(ctx context.Context, ter
 	client := &http.Client{Timeout: 10 * time.Second}
 	page := 1
 
-	fetchFrom := "2010-01-01+00:00:00"
-	today := time.Now().Format("2006-01-02") + "+23:59:59"
+	erased, err := loadErasedUsers(ctx, s.privacyRepo)
+	if err != nil {
+		return err
+	}
+
+	dateRange := chocotime.NewDateRange(time.Date(2010, time.January, 1, 0, 0, 0, 0, chocotime.Location), time.Now())
 	baseUrl := fmt.Sprintf(
-		"https://api-proxy.choco.kz/analytics/v1/customers?terminals=%s&sort=turnover&filter[start_date]=%s&filter[end_date]=%s",
+		"https://api-proxy.choco.kz/analytics/v1/customers?terminals=%s&sort=turnover&%s",
 		terminals,
-		fetchFrom,
-		today,
+		dateRange.Query("filter[start_date]", "filter[end_date]"),
 	)
 
 	for {
@@ -80,18 +88,33 @@ func (s *CompanyCustomersService) FetchCompanyCustomers(ctx context.Context, ter
 		}
 
 		for _, item := range response.Data {
+			if erased.has(item.Attributes.UserID) {
+				continue
+			}
+
 			fmt.Println("Processing customer ID: ", item.ID)
 
+			var lastVisit time.Time
+			if item.Attributes.LastVisitDate != "" {
+				lastVisit, err = chocotime.Parse(item.Attributes.LastVisitDate)
+				if err != nil {
+					return fmt.Errorf("failed to parse last visit date of customer %d: %w", item.ID, err)
+				}
+			}
+
+			normalizedPhone, _ := phone.Normalize(item.Attributes.Phone)
+
 			customer := domain.CompanyCustomer{
-				ID:            item.ID,
-				Company:       companyName,
-				UserID:        item.Attributes.UserID,
-				FullName:      item.Attributes.FullName,
-				Phone:         item.Attributes.Phone,
-				Turnover:      item.Attributes.Turnover,
-				LastVisitDate: item.Attributes.LastVisitDate,
-				VisitsCount:   item.Attributes.VisitsCount,
-				AverageBill:   item.Attributes.AverageBill,
+				ID:              item.ID,
+				Company:         companyName,
+				UserID:          item.Attributes.UserID,
+				FullName:        item.Attributes.FullName,
+				Phone:           item.Attributes.Phone,
+				NormalizedPhone: normalizedPhone,
+				Turnover:        item.Attributes.Turnover,
+				LastVisitDate:   lastVisit,
+				VisitsCount:     item.Attributes.VisitsCount,
+				AverageBill:     item.Attributes.AverageBill,
 			}
 
 			if err := s.companyCustomerRepo.Store(ctx, &customer); err != nil {
@@ -106,5 +129,9 @@ func (s *CompanyCustomersService) FetchCompanyCustomers(ctx context.Context, ter
 		page++
 	}
 
+	if err := s.companyCustomerRepo.Snapshot(ctx, companyName, time.Now()); err != nil {
+		return fmt.Errorf("failed to snapshot company customers: %w", err)
+	}
+
 	return nil
 }
@@ -8,6 +8,8 @@ import (
 
 	"github.com/ibookerke/choco_parser_go/internal/config"
 	"github.com/ibookerke/choco_parser_go/internal/domain"
+	"github.com/ibookerke/choco_parser_go/internal/pkg/chocotime"
+	"github.com/ibookerke/choco_parser_go/internal/pkg/phone"
 	"github.com/ibookerke/choco_parser_go/internal/pkg/trm"
 )
 
@@ -61,6 +63,16 @@ func (c *CustomerService) FetchCustomerInfo(ctx context.Context, id domain.Custo
 	return customer, nil
 }
 
+// FindPhoneDuplicates lists normalized phones shared by more than one user ID.
+func (c *CustomerService) FindPhoneDuplicates(ctx context.Context) ([]domain.PhoneDuplicate, error) {
+	duplicates, err := c.customerRepo.FindPhoneDuplicates(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find phone duplicates: %w", err)
+	}
+
+	return duplicates, nil
+}
+
 type CustomerResponseData struct {
 	Jsonapi struct {
 		Version string `json:"version"`
@@ -132,13 +144,27 @@ func (c *CustomerService) getFetchedCustomer(ctx context.Context, id domain.Cust
 		return domain.Customer{}, fmt.Errorf("failed to unmarshal JSON: %v", err)
 	}
 
+	// birthday is optional profile data, an unknown format shouldn't stop the sync
+	var birthday domain.Date
+	if raw := responseData.Data.Attributes.Birthday; raw != "" {
+		y, m, d, err := chocotime.ParseDate(raw)
+		if err != nil {
+			fmt.Println("failed to parse birthday of customer "+customerId+": ", err)
+		} else {
+			birthday = domain.NewDate(y, m, d)
+		}
+	}
+
+	normalizedPhone, _ := phone.Normalize(responseData.Data.Attributes.Phone)
+
 	customer := domain.Customer{
-		ID:         id,
-		UserID:     responseData.Data.Attributes.UserID,
-		FullName:   responseData.Data.Attributes.FullName,
-		Phone:      responseData.Data.Attributes.Phone,
-		Birthday:   responseData.Data.Attributes.Birthday,
-		OrderCount: responseData.Data.Attributes.Statistics.OrdersCount,
+		ID:              id,
+		UserID:          responseData.Data.Attributes.UserID,
+		FullName:        responseData.Data.Attributes.FullName,
+		Phone:           responseData.Data.Attributes.Phone,
+		NormalizedPhone: normalizedPhone,
+		Birthday:        birthday,
+		OrderCount:      responseData.Data.Attributes.Statistics.OrdersCount,
 	}
 
 	return customer, nil
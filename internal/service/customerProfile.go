@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ibookerke/choco_parser_go/internal/domain"
+)
+
+// recentLimit is how many payments and reviews a profile shows.
+const recentLimit = 10
+
+type CustomerProfileService struct {
+	profileRepo domain.CustomerProfileRepository
+	paymentRepo domain.PaymentRepository
+	reviewRepo  domain.ReviewRepository
+}
+
+func NewCustomerProfileService(
+	profileRepo domain.CustomerProfileRepository,
+	paymentRepo domain.PaymentRepository,
+	reviewRepo domain.ReviewRepository,
+) *CustomerProfileService {
+	return &CustomerProfileService{
+		profileRepo: profileRepo,
+		paymentRepo: paymentRepo,
+		reviewRepo:  reviewRepo,
+	}
+}
+
+// CustomerOverview is a profile with the latest activity of the customer.
+type CustomerOverview struct {
+	Profile        domain.CustomerProfile
+	RecentPayments []domain.Payment
+	RecentReviews  []domain.Review
+}
+
+func (s *CustomerProfileService) GetOverview(ctx context.Context, userID int64) (CustomerOverview, error) {
+	profile, err := s.profileRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return CustomerOverview{}, fmt.Errorf("failed to find customer profile: %w", err)
+	}
+
+	payments, err := s.paymentRepo.FindByUserID(ctx, userID, recentLimit)
+	if err != nil {
+		return CustomerOverview{}, fmt.Errorf("failed to find customer payments: %w", err)
+	}
+
+	reviews, err := s.reviewRepo.FindByUserID(ctx, userID, recentLimit)
+	if err != nil {
+		return CustomerOverview{}, fmt.Errorf("failed to find customer reviews: %w", err)
+	}
+
+	return CustomerOverview{
+		Profile:        profile,
+		RecentPayments: payments,
+		RecentReviews:  reviews,
+	}, nil
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/ibookerke/choco_parser_go/internal/domain"
+	"github.com/ibookerke/choco_parser_go/internal/pkg/chocotime"
+	"github.com/ibookerke/choco_parser_go/internal/pkg/forecast"
+	"github.com/ibookerke/choco_parser_go/internal/pkg/trm"
+)
+
+// forecastZ is the width of the 95% prediction interval in standard deviations.
+const forecastZ = 1.96
+
+const weekDays = 7
+
+type ForecastService struct {
+	revenueRepo  domain.RevenueRepository
+	forecastRepo domain.ForecastRepository
+	trm          trm.Manager
+}
+
+func NewForecastService(
+	revenueRepo domain.RevenueRepository,
+	forecastRepo domain.ForecastRepository,
+	trm trm.Manager,
+) *ForecastService {
+	return &ForecastService{
+		revenueRepo:  revenueRepo,
+		forecastRepo: forecastRepo,
+		trm:          trm,
+	}
+}
+
+// Forecast predicts the daily net revenue of the location for the days from today on, with
+// 95% prediction intervals, and stores the predictions. The model is fitted to the last
+// historyDays complete days of daily_revenue, starting from the first day with revenue.
+func (s *ForecastService) Forecast(
+	ctx context.Context,
+	location string,
+	days, historyDays int,
+	now time.Time,
+) ([]domain.Forecast, error) {
+	if days < 1 {
+		return nil, fmt.Errorf("days must be at least 1, got %d", days)
+	}
+	if historyDays < 2*weekDays {
+		return nil, fmt.Errorf("history days must be at least %d, got %d", 2*weekDays, historyDays)
+	}
+
+	local := now.In(chocotime.Location)
+	today := domain.DateOf(local)
+	from := domain.DateOf(local.AddDate(0, 0, -historyDays))
+	to := domain.DateOf(local.AddDate(0, 0, -1))
+
+	revenue, err := s.revenueRepo.LocationDailyRevenue(ctx, location, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get daily revenue: %w", err)
+	}
+
+	series := dailySeries(revenue, from, to)
+
+	model, err := forecast.Fit(series, weekDays)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fit forecast model for %q: %w", location, err)
+	}
+
+	forecasts := make([]domain.Forecast, 0, days)
+	for k, p := range model.Forecast(days, forecastZ) {
+		forecasts = append(forecasts, domain.Forecast{
+			Location:  location,
+			MadeOn:    today,
+			Day:       domain.DateOf(today.In(time.UTC).AddDate(0, 0, k)),
+			Predicted: moneyOf(p.Value),
+			Lower:     moneyOf(math.Max(p.Lower, 0)),
+			Upper:     moneyOf(p.Upper),
+			Model:     fmt.Sprintf("holt-winters weekly %s sigma=%.2f", model.Params, model.Sigma),
+		})
+	}
+
+	err = s.trm.Do(ctx, func(ctx context.Context) error {
+		return s.forecastRepo.Store(ctx, forecasts)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to store forecasts: %w", err)
+	}
+
+	return forecasts, nil
+}
+
+// dailySeries sums the revenue per day from the first day with revenue to to, in tenge.
+// Days without a row had no revenue.
+func dailySeries(revenue []domain.LocationRevenue, from, to domain.Date) []float64 {
+	series := make([]float64, from.DaysUntil(to)+1)
+	first := len(series)
+	for _, r := range revenue {
+		i := from.DaysUntil(r.Day)
+		if i < 0 || i >= len(series) {
+			continue
+		}
+		series[i] += r.Net.Float64()
+		if r.Net != 0 {
+			first = min(first, i)
+		}
+	}
+
+	return series[first:]
+}
+
+// moneyOf rounds a model value in tenge to tiyn.
+func moneyOf(tenge float64) domain.Money {
+	return domain.Money(math.Round(tenge * 100))
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ibookerke/choco_parser_go/internal/domain"
+	"github.com/ibookerke/choco_parser_go/internal/pkg/chocotime"
+	"github.com/ibookerke/choco_parser_go/internal/pkg/trm"
+)
+
+type LifecycleService struct {
+	lifecycleRepo domain.LifecycleRepository
+	trm           trm.Manager
+}
+
+func NewLifecycleService(
+	lifecycleRepo domain.LifecycleRepository,
+	trm trm.Manager,
+) *LifecycleService {
+	return &LifecycleService{
+		lifecycleRepo: lifecycleRepo,
+		trm:           trm,
+	}
+}
+
+// Update classifies every customer with payments at now and records the customers whose stage changed.
+// It returns the number of transitions. The first run only seeds the stages, it records no events
+// so the transition counts don't start with every customer entering a stage at once.
+func (s *LifecycleService) Update(ctx context.Context, now time.Time) (int, error) {
+	var transitions int
+
+	err := s.trm.Do(ctx, func(ctx context.Context) error {
+		facts, err := s.lifecycleRepo.Facts(ctx, domain.LifecycleLapsedAfter)
+		if err != nil {
+			return fmt.Errorf("failed to get lifecycle facts: %w", err)
+		}
+
+		current, err := s.lifecycleRepo.CurrentStages(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get current lifecycle stages: %w", err)
+		}
+
+		seed := len(current) == 0
+		for _, f := range facts {
+			stage := domain.LifecycleStage(f, now)
+			if seed {
+				if err := s.lifecycleRepo.SetStage(ctx, f.UserID, stage, now); err != nil {
+					return fmt.Errorf("failed to seed lifecycle stage of user %d: %w", f.UserID, err)
+				}
+				continue
+			}
+			if current[f.UserID] == stage {
+				continue
+			}
+
+			event := domain.LifecycleEvent{UserID: f.UserID, From: current[f.UserID], To: stage, ChangedAt: now}
+			if err := s.lifecycleRepo.RecordEvent(ctx, &event); err != nil {
+				return fmt.Errorf("failed to record lifecycle event of user %d: %w", f.UserID, err)
+			}
+			transitions++
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return transitions, nil
+}
+
+// TransitionCounts counts the stage changes per day between from and to inclusive.
+func (s *LifecycleService) TransitionCounts(ctx context.Context, from, to domain.Date) ([]domain.LifecycleTransitionCount, error) {
+	counts, err := s.lifecycleRepo.TransitionCounts(ctx, from, to, chocotime.Location.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lifecycle transition counts: %w", err)
+	}
+
+	return counts, nil
+}
@@ -10,12 +10,17 @@ import (
 
 	"github.com/ibookerke/choco_parser_go/internal/config"
 	"github.com/ibookerke/choco_parser_go/internal/domain"
+	"github.com/ibookerke/choco_parser_go/internal/pkg/chocotime"
 	"github.com/ibookerke/choco_parser_go/internal/pkg/trm"
 )
 
 type PaymentService struct {
 	paymentRepo  domain.PaymentRepository
 	customerRepo domain.CustomerRepository
+	branchRepo   domain.BranchRepository
+	reviewRepo   domain.ReviewRepository
+	privacyRepo  domain.PrivacyRepository
+	staffRepo    domain.StaffRepository
 	authRepo     domain.AuthRepository
 	trm          trm.Manager
 	cfg          config.Choco
@@ -24,6 +29,10 @@ type PaymentService struct {
 func NewPaymentService(
 	paymentRepository domain.PaymentRepository,
 	customerRepository domain.CustomerRepository,
+	branchRepository domain.BranchRepository,
+	reviewRepository domain.ReviewRepository,
+	privacyRepository domain.PrivacyRepository,
+	staffRepository domain.StaffRepository,
 	authRepository domain.AuthRepository,
 	trm trm.Manager,
 	cfg config.Choco,
@@ -31,6 +40,10 @@ func NewPaymentService(
 	return &PaymentService{
 		paymentRepo:  paymentRepository,
 		customerRepo: customerRepository,
+		branchRepo:   branchRepository,
+		reviewRepo:   reviewRepository,
+		privacyRepo:  privacyRepository,
+		staffRepo:    staffRepository,
 		authRepo:     authRepository,
 		trm:          trm,
 		cfg:          cfg,
@@ -110,40 +123,95 @@ func (s *PaymentService) fetchUniqueUserIDs(ctx context.Context, baseURL string)
 	return userIDs, nil
 }
 
-func (s *PaymentService) FetchPayments(ctx context.Context, terminals string) error {
+// FetchPayments syncs the payments of the last days and returns the synced range.
+func (s *PaymentService) FetchPayments(ctx context.Context, terminals string) (chocotime.DateRange, error) {
 	customerService := NewCustomerService(s.customerRepo, s.authRepo, s.trm, s.cfg)
 
-	var endDate = time.Now().Format("2006-01-02") + "+23:59:59"
-	var startDate = time.Now().AddDate(0, 0, -2).Format("2006-01-02") + "+00:00:00"
+	dateRange := chocotime.LastDays(time.Now(), 2)
 
 	baseURL := "https://api-proxy.choco.kz/acl/proxy?proxy_path=reports/merchant/transactions&filials=" +
 		terminals +
-		"&types=pay,refund&start_date=" + startDate + "&end_date=" + endDate
+		"&types=pay,refund&" + dateRange.Query("start_date", "end_date")
+
+	resolver, err := newBranchResolver(ctx, s.branchRepo, terminals)
+	if err != nil {
+		return chocotime.DateRange{}, fmt.Errorf("failed to build branch resolver: %w", err)
+	}
+
+	erased, err := loadErasedUsers(ctx, s.privacyRepo)
+	if err != nil {
+		return chocotime.DateRange{}, err
+	}
 
 	fmt.Println("fetching user IDs")
 	userIDs, err := s.fetchUniqueUserIDs(ctx, baseURL)
 	if err != nil {
-		return fmt.Errorf("failed to fetch unique user IDs: %w", err)
+		return chocotime.DateRange{}, fmt.Errorf("failed to fetch unique user IDs: %w", err)
 	}
 
 	fmt.Println(string(rune(len(userIDs))) + " customers found")
 
 	for _, userID := range userIDs {
-		fmt.Println("fetching customer info for user ID: " + strconv.FormatInt(userID, 10))
-		_, err := customerService.FetchCustomerInfo(ctx, domain.CustomerID(userID), terminals)
-		if err != nil {
-			return fmt.Errorf("failed to fetch customer info: %w", err)
+		// erased users keep contributing revenue, but their profile isn't fetched again
+		if !erased.has(userID) {
+			fmt.Println("fetching customer info for user ID: " + strconv.FormatInt(userID, 10))
+			_, err := customerService.FetchCustomerInfo(ctx, domain.CustomerID(userID), terminals)
+			if err != nil {
+				return chocotime.DateRange{}, fmt.Errorf("failed to fetch customer info: %w", err)
+			}
 		}
 
 		fmt.Println("fetching user payments for user ID: " + strconv.FormatInt(userID, 10))
-		if err := s.fetchUserPayments(ctx, terminals, userID, startDate, endDate); err != nil {
-			return fmt.Errorf("failed to fetch user payments: %w", err)
+		if err := s.fetchUserPayments(ctx, terminals, resolver, erased, userID, dateRange); err != nil {
+			return chocotime.DateRange{}, fmt.Errorf("failed to fetch user payments: %w", err)
 		}
 
 		// sleep for 3 sec
 		//time.Sleep(3 * time.Second)
 	}
 
+	return dateRange, nil
+}
+
+// LinkRefunds matches the unlinked refunds of the last RefundRetryWindow to the payments they reverse,
+// oldest refunds first so they take the payments they match before later ones. Refunds nothing matches
+// stay unlinked and are retried on the next syncs, when their payment may have been synced.
+func (s *PaymentService) LinkRefunds(ctx context.Context, now time.Time) error {
+	refunds, err := s.paymentRepo.FindUnlinkedRefunds(ctx, now.Add(-domain.RefundRetryWindow))
+	if err != nil {
+		return fmt.Errorf("failed to find unlinked refunds: %w", err)
+	}
+
+	var unmatched int
+	for _, refund := range refunds {
+		candidates, err := s.paymentRepo.FindRefundCandidates(
+			ctx,
+			refund.UserID,
+			refund.CreatedAt.Add(-domain.RefundWindow),
+			refund.CreatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to find refund candidates: %w", err)
+		}
+
+		payment := domain.MatchRefund(refund, candidates)
+		if payment == nil {
+			unmatched++
+			continue
+		}
+
+		err = s.trm.Do(ctx, func(ctx context.Context) error {
+			return s.paymentRepo.LinkRefund(ctx, refund.ID, payment.ID, refund.RefundAmount())
+		})
+		if err != nil {
+			return fmt.Errorf("failed to link refund %d: %w", refund.ID, err)
+		}
+	}
+
+	if unmatched > 0 {
+		fmt.Println(strconv.Itoa(unmatched) + " refunds couldn't be matched to a payment yet")
+	}
+
 	return nil
 }
 
@@ -170,26 +238,33 @@ type PHDataItem struct {
 
 type PHAttribute struct {
 	Transaction struct {
-		ID             int64   `json:"id"`
-		CreatedBy      int64   `json:"created_by"`
-		Type           string  `json:"type"`
-		Amount         float64 `json:"amount"`
-		DiscountAmount float64 `json:"discount_amount"`
-		CreatedAt      string  `json:"created_at"`
+		ID             int64        `json:"id"`
+		CreatedBy      int64        `json:"created_by"`
+		Type           string       `json:"type"`
+		Amount         domain.Money `json:"amount"`
+		DiscountAmount domain.Money `json:"discount_amount"`
+		CreatedAt      string       `json:"created_at"`
 	} `json:"transaction"`
 	Location struct {
 		Title     string `json:"title"`
 		PartnerID string `json:"partner_id"`
 	} `json:"location"`
-	Review interface{} `json:"review"`
+	Review json.RawMessage `json:"review"`
+}
+
+type PHReview struct {
+	Rating    int    `json:"rating"`
+	Comment   string `json:"comment"`
+	CreatedAt string `json:"created_at"`
 }
 
 func (s *PaymentService) fetchUserPayments(
 	ctx context.Context,
 	terminals string,
+	resolver *branchResolver,
+	erased erasedUsers,
 	userId int64,
-	startDate string,
-	endDate string,
+	dateRange chocotime.DateRange,
 ) error {
 	client := &http.Client{}
 	page := 1
@@ -198,7 +273,7 @@ func (s *PaymentService) fetchUserPayments(
 		strconv.FormatInt(userId, 10) +
 		"/payment-history?terminals=" +
 		terminals +
-		"&filter[start_date]=" + startDate + "&filter[end_date]=" + endDate
+		"&" + dateRange.Query("filter[start_date]", "filter[end_date]")
 
 	for {
 		// Construct the paginated URL
@@ -234,7 +309,7 @@ func (s *PaymentService) fetchUserPayments(
 		}
 
 		for _, item := range responseData.Data {
-			err := s.storePayment(ctx, item)
+			err := s.storePayment(ctx, resolver, erased, userId, item)
 			if err != nil {
 				return fmt.Errorf("failed to store payment: %w", err)
 			}
@@ -250,30 +325,103 @@ func (s *PaymentService) fetchUserPayments(
 	return nil
 }
 
-func (s *PaymentService) storePayment(ctx context.Context, item PHDataItem) error {
+func (s *PaymentService) storePayment(
+	ctx context.Context,
+	resolver *branchResolver,
+	erased erasedUsers,
+	userId int64,
+	item PHDataItem,
+) error {
+	createdAt, err := chocotime.Parse(item.Attributes[0].Transaction.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to parse payment created_at: %w", err)
+	}
+
 	payment := domain.Payment{
 		ID:                domain.PaymentID(item.Attributes[0].Transaction.ID),
+		UserID:            userId,
 		CreatedBy:         item.Attributes[0].Transaction.CreatedBy,
-		Type:              item.Attributes[0].Transaction.Type,
-		Amount:            int64(item.Attributes[0].Transaction.Amount),
-		DiscountAmount:    int64(item.Attributes[0].Transaction.DiscountAmount),
-		CreatedAt:         item.Attributes[0].Transaction.CreatedAt,
+		Type:              domain.PaymentType(item.Attributes[0].Transaction.Type),
+		Amount:            item.Attributes[0].Transaction.Amount,
+		DiscountAmount:    item.Attributes[0].Transaction.DiscountAmount,
+		CreatedAt:         createdAt,
 		LocationTitle:     item.Attributes[0].Location.Title,
 		LocationPartnerID: item.Attributes[0].Location.PartnerID,
 	}
 
+	if erased.has(payment.UserID) {
+		payment.UserID = 0
+	}
+	if erased.has(payment.CreatedBy) {
+		payment.CreatedBy = 0
+	}
+
+	if branch := resolver.resolve(payment.LocationPartnerID, payment.LocationTitle); branch != nil {
+		payment.BranchID = branch.ID
+		payment.LocationID = branch.LocationID
+	} else {
+		fmt.Println("couldn't attribute payment to a terminal: ", payment.ID, payment.LocationTitle)
+	}
+
 	exists, err := s.paymentRepo.ExistsById(ctx, payment.ID)
 	if err != nil {
 		return fmt.Errorf("failed to check if payment exists: %w", err)
 	}
 
-	if exists {
+	if !exists {
+		_, err = s.paymentRepo.Create(ctx, &payment)
+		if err != nil {
+			return fmt.Errorf("failed to store payment: %w", err)
+		}
+
+		if payment.CreatedBy != 0 {
+			if err := s.staffRepo.Touch(ctx, payment.CreatedBy, payment.CreatedAt); err != nil {
+				return fmt.Errorf("failed to store staff: %w", err)
+			}
+		}
+	} else if payment.UserID != 0 {
+		if err := s.paymentRepo.FillUserID(ctx, payment.ID, payment.UserID); err != nil {
+			return fmt.Errorf("failed to fill payment user id: %w", err)
+		}
+	}
+
+	if payment.UserID == 0 {
 		return nil
 	}
-	_, err = s.paymentRepo.Create(ctx, &payment)
-	if err != nil {
-		return fmt.Errorf("failed to store payment: %w", err)
+
+	// reviews can be left after the payment was stored, so they are upserted on every sync
+	if err := s.storeReview(ctx, payment, item.Attributes[0].Review); err != nil {
+		return fmt.Errorf("failed to store review: %w", err)
 	}
 
 	return nil
 }
+
+func (s *PaymentService) storeReview(ctx context.Context, payment domain.Payment, raw json.RawMessage) error {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+
+	var phReview PHReview
+	if err := json.Unmarshal(raw, &phReview); err != nil {
+		return fmt.Errorf("failed to unmarshal review: %w", err)
+	}
+
+	review := domain.Review{
+		PaymentID: payment.ID,
+		UserID:    payment.UserID,
+		Rating:    phReview.Rating,
+		Comment:   phReview.Comment,
+		CreatedAt: payment.CreatedAt,
+		Raw:       raw,
+	}
+	if phReview.CreatedAt != "" {
+		createdAt, err := chocotime.Parse(phReview.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to parse review created_at: %w", err)
+		}
+		review.CreatedAt = createdAt
+	}
+
+	return s.reviewRepo.Store(ctx, &review)
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ibookerke/choco_parser_go/internal/domain"
+	"github.com/ibookerke/choco_parser_go/internal/pkg/trm"
+)
+
+type PrivacyService struct {
+	privacyRepo domain.PrivacyRepository
+	trm         trm.Manager
+}
+
+func NewPrivacyService(
+	privacyRepo domain.PrivacyRepository,
+	trm trm.Manager,
+) *PrivacyService {
+	return &PrivacyService{
+		privacyRepo: privacyRepo,
+		trm:         trm,
+	}
+}
+
+// Erase purges the user from customers, company_customers and reviews, unlinks their payments
+// and records the erasure in one transaction. Erasing the same user again is safe.
+func (s *PrivacyService) Erase(ctx context.Context, userID int64) (domain.Erasure, error) {
+	var erasure domain.Erasure
+
+	err := s.trm.Do(ctx, func(ctx context.Context) error {
+		var err error
+		erasure, err = s.privacyRepo.EraseUser(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("failed to erase user: %w", err)
+		}
+
+		erasure.ErasedAt = time.Now()
+		if err := s.privacyRepo.RecordErasure(ctx, &erasure); err != nil {
+			return fmt.Errorf("failed to record erasure: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return domain.Erasure{}, err
+	}
+
+	return erasure, nil
+}
+
+// erasedUsers is the set of erased user IDs the syncs must not ingest PII for.
+type erasedUsers map[int64]struct{}
+
+func loadErasedUsers(ctx context.Context, privacyRepo domain.PrivacyRepository) (erasedUsers, error) {
+	ids, err := privacyRepo.ErasedUserIDs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load erased users: %w", err)
+	}
+
+	users := make(erasedUsers, len(ids))
+	for _, id := range ids {
+		users[id] = struct{}{}
+	}
+
+	return users, nil
+}
+
+func (e erasedUsers) has(userID int64) bool {
+	_, ok := e[userID]
+	return ok
+}
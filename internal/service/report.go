@@ -0,0 +1,360 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/ibookerke/choco_parser_go/internal/domain"
+	"github.com/ibookerke/choco_parser_go/internal/pkg/chocotime"
+)
+
+type ReportService struct {
+	reportRepo domain.ReportRepository
+	branchRepo domain.BranchRepository
+}
+
+func NewReportService(
+	reportRepo domain.ReportRepository,
+	branchRepo domain.BranchRepository,
+) *ReportService {
+	return &ReportService{
+		reportRepo: reportRepo,
+		branchRepo: branchRepo,
+	}
+}
+
+// CohortRow is one first-payment month with the share of its customers
+// returning in each following month, Retention[0] is always 100.
+type CohortRow struct {
+	Cohort    domain.Date
+	Customers int64
+	Retention []float64
+}
+
+// Cohorts groups the customers of the company by the month of their first payment.
+// Every row runs up to the latest month with payments, so later cohorts have shorter rows.
+func (s *ReportService) Cohorts(ctx context.Context, company string) ([]CohortRow, error) {
+	branchIds, err := companyBranchIds(ctx, s.branchRepo, company)
+	if err != nil {
+		return nil, err
+	}
+
+	cells, err := s.reportRepo.CohortCounts(ctx, branchIds, chocotime.Location.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cohort counts: %w", err)
+	}
+
+	return cohortRows(cells), nil
+}
+
+// cohortRows pivots the cells, ordered by cohort and offset, into one row per cohort
+// padded with zeros up to the latest month any cohort paid in.
+func cohortRows(cells []domain.CohortCell) []CohortRow {
+	var (
+		rows   []CohortRow
+		latest int
+	)
+	for _, cell := range cells {
+		latest = max(latest, monthIndex(cell.Cohort)+cell.Offset)
+
+		if len(rows) == 0 || rows[len(rows)-1].Cohort != cell.Cohort {
+			rows = append(rows, CohortRow{Cohort: cell.Cohort})
+		}
+		row := &rows[len(rows)-1]
+
+		if cell.Offset == 0 {
+			row.Customers = cell.Customers
+		}
+		for len(row.Retention) <= cell.Offset {
+			row.Retention = append(row.Retention, 0)
+		}
+		row.Retention[cell.Offset] = share(cell.Customers, row.Customers)
+	}
+
+	// pad every cohort up to the latest month with payments
+	for i := range rows {
+		months := latest - monthIndex(rows[i].Cohort) + 1
+		for len(rows[i].Retention) < months {
+			rows[i].Retention = append(rows[i].Retention, 0)
+		}
+	}
+
+	return rows
+}
+
+// monthIndex numbers calendar months continuously across years.
+func monthIndex(d domain.Date) int {
+	return d.Year*12 + int(d.Month) - 1
+}
+
+// ChurnRisk is a high-value customer whose current visit gap exceeds their typical interval.
+type ChurnRisk struct {
+	domain.ChurnCandidate
+	GapDays             int
+	TypicalIntervalDays float64
+	IntervalSource      string
+	MissedVisits        int64
+	ExpectedLoss        domain.Money
+}
+
+// ChurnRisks lists the company customers inactive for at least inactiveDays with turnover of
+// at least minTurnover whose gap since the last visit exceeds their typical interval: the mean gap
+// between their synced payments, else the mean gap seen across the company_customers syncs, else
+// inactiveDays. IntervalSource tells which one a row was measured against.
+// The expected loss is the average bill times the visits missed so far.
+func (s *ReportService) ChurnRisks(
+	ctx context.Context,
+	company string,
+	inactiveDays int,
+	minTurnover domain.Money,
+	now time.Time,
+) ([]ChurnRisk, error) {
+	if inactiveDays < 1 {
+		return nil, fmt.Errorf("inactive days must be at least 1, got %d", inactiveDays)
+	}
+
+	branchIds, err := companyBranchIds(ctx, s.branchRepo, company)
+	if err != nil {
+		return nil, err
+	}
+
+	today := chocotime.StartOfDay(now)
+	candidates, err := s.reportRepo.ChurnCandidates(ctx, company, branchIds, today.AddDate(0, 0, -inactiveDays), minTurnover)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get churn candidates: %w", err)
+	}
+
+	var risks []ChurnRisk
+	for _, c := range candidates {
+		interval, source := float64(inactiveDays), domain.ChurnIntervalInactive
+		switch {
+		case c.IntervalDays != nil && *c.IntervalDays >= 1:
+			interval, source = *c.IntervalDays, domain.ChurnIntervalPayments
+		case c.VisitIntervalDays != nil && *c.VisitIntervalDays >= 1:
+			interval, source = *c.VisitIntervalDays, domain.ChurnIntervalVisits
+		}
+
+		gap := daysBetween(c.LastVisit, today)
+		if float64(gap) <= interval {
+			continue
+		}
+
+		missed := int64(math.Floor(float64(gap) / interval))
+		risks = append(risks, ChurnRisk{
+			ChurnCandidate:      c,
+			GapDays:             gap,
+			TypicalIntervalDays: interval,
+			IntervalSource:      source,
+			MissedVisits:        missed,
+			ExpectedLoss:        c.AverageBill.MulRat(missed, 1),
+		})
+	}
+
+	sort.SliceStable(risks, func(i, j int) bool { return risks[i].ExpectedLoss > risks[j].ExpectedLoss })
+
+	return risks, nil
+}
+
+// UpcomingBirthday is a company customer with a birthday within the window.
+type UpcomingBirthday struct {
+	domain.BirthdayCandidate
+	NextBirthday domain.Date
+	DaysLeft     int
+	Age          int
+}
+
+// UpcomingBirthdays lists the company customers whose birthday falls within the next days,
+// today included so 14 days run up to 13 days from today, ordered by date.
+// Feb 29 birthdays are celebrated on Feb 28 in common years.
+func (s *ReportService) UpcomingBirthdays(ctx context.Context, company string, days int, now time.Time) ([]UpcomingBirthday, error) {
+	candidates, err := s.reportRepo.BirthdayCandidates(ctx, company)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get birthday candidates: %w", err)
+	}
+
+	today := domain.DateOf(now.In(chocotime.Location))
+
+	var birthdays []UpcomingBirthday
+	for _, c := range candidates {
+		next, daysLeft, ok := c.Birthday.AnniversaryWithin(today, days)
+		if !ok {
+			continue
+		}
+
+		birthdays = append(birthdays, UpcomingBirthday{
+			BirthdayCandidate: c,
+			NextBirthday:      next,
+			DaysLeft:          daysLeft,
+			Age:               next.Year - c.Birthday.Year,
+		})
+	}
+
+	sort.SliceStable(birthdays, func(i, j int) bool {
+		if birthdays[i].DaysLeft != birthdays[j].DaysLeft {
+			return birthdays[i].DaysLeft < birthdays[j].DaysLeft
+		}
+		return birthdays[i].Turnover > birthdays[j].Turnover
+	})
+
+	return birthdays, nil
+}
+
+// DiscountRow is the discount effectiveness of a location in a month.
+// DiscountShare is the percentage of the pre-discount revenue given away as discounts.
+// The return rates are the percentages of customers with and without a discount
+// who paid at the location again within the return window.
+type DiscountRow struct {
+	domain.DiscountCell
+	DiscountShare        float64
+	AverageDiscount      domain.Money
+	AverageGivenDiscount domain.Money
+	DiscountedReturnRate float64
+	OtherReturnRate      float64
+}
+
+// Discounts reports discounts per location and month between from and to inclusive.
+// Customers of the last returnDays are not able to return yet, so recent months understate both rates.
+func (s *ReportService) Discounts(ctx context.Context, from, to domain.Date, returnDays int) ([]DiscountRow, error) {
+	if returnDays < 1 {
+		return nil, fmt.Errorf("return window must be at least one day, got %d", returnDays)
+	}
+
+	cells, err := s.reportRepo.DiscountCells(ctx, from, to, chocotime.Location.String(), returnDays)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get discount cells: %w", err)
+	}
+
+	rows := make([]DiscountRow, 0, len(cells))
+	for _, c := range cells {
+		rows = append(rows, DiscountRow{
+			DiscountCell:         c,
+			DiscountShare:        share(int64(c.Discounts), int64(c.Amount+c.Discounts)),
+			AverageDiscount:      c.Discounts.MulRat(1, c.Payments),
+			AverageGivenDiscount: c.Discounts.MulRat(1, c.DiscountedPayments),
+			DiscountedReturnRate: share(c.DiscountedReturned, c.DiscountedCustomers),
+			OtherReturnRate:      share(c.OtherReturned, c.OtherCustomers),
+		})
+	}
+
+	return rows, nil
+}
+
+// Overlap compares the customers of two companies.
+type Overlap struct {
+	CompanyA  string
+	CompanyB  string
+	Customers []domain.OverlapCustomer
+	TurnoverA domain.Money
+	TurnoverB domain.Money
+	Switchers []Switcher
+}
+
+// Switcher is a customer whose latest visit moved from one company to the other between snapshots.
+type Switcher struct {
+	UserID        int64
+	FullName      string
+	Phone         string
+	From          string
+	To            string
+	PrevLastVisit time.Time
+	LastVisit     time.Time
+}
+
+// Overlap finds the customers visiting both companies and the ones who switched between them.
+// A customer switched from A to B when their latest visit was to A as of the previous
+// snapshots and is to B as of the latest ones, so switchers need two synced runs of each company.
+func (s *ReportService) Overlap(ctx context.Context, companyA, companyB string) (*Overlap, error) {
+	if companyA == companyB {
+		return nil, fmt.Errorf("cannot compare company %q with itself", companyA)
+	}
+
+	customers, err := s.reportRepo.OverlapCustomers(ctx, companyA, companyB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get overlap customers: %w", err)
+	}
+
+	overlap := &Overlap{CompanyA: companyA, CompanyB: companyB, Customers: customers}
+	for _, c := range customers {
+		overlap.TurnoverA += c.TurnoverA
+		overlap.TurnoverB += c.TurnoverB
+	}
+
+	candidates, err := s.reportRepo.SwitchCandidates(ctx, companyA, companyB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get switch candidates: %w", err)
+	}
+
+	for _, c := range candidates {
+		wasA := c.PrevLastVisitA.After(c.PrevLastVisitB)
+		wasB := c.PrevLastVisitB.After(c.PrevLastVisitA)
+		isA := c.LastVisitA.After(c.LastVisitB)
+		isB := c.LastVisitB.After(c.LastVisitA)
+
+		switcher := Switcher{UserID: c.UserID, FullName: c.FullName, Phone: c.Phone}
+		switch {
+		case wasA && isB:
+			switcher.From, switcher.To = companyA, companyB
+			switcher.PrevLastVisit, switcher.LastVisit = c.PrevLastVisitA, c.LastVisitB
+		case wasB && isA:
+			switcher.From, switcher.To = companyB, companyA
+			switcher.PrevLastVisit, switcher.LastVisit = c.PrevLastVisitB, c.LastVisitA
+		default:
+			continue
+		}
+		overlap.Switchers = append(overlap.Switchers, switcher)
+	}
+
+	sort.SliceStable(overlap.Switchers, func(i, j int) bool {
+		return overlap.Switchers[i].LastVisit.After(overlap.Switchers[j].LastVisit)
+	})
+
+	return overlap, nil
+}
+
+// Heatmap holds payment counts and revenue per weekday, Monday first, and hour in local time.
+type Heatmap struct {
+	Payments [7][24]int64
+	Revenue  [7][24]domain.Money
+}
+
+// Heatmap sums the payments of the location between from and to inclusive by weekday and hour.
+func (s *ReportService) Heatmap(ctx context.Context, location string, from, to domain.Date) (*Heatmap, error) {
+	cells, err := s.reportRepo.HeatmapCells(ctx, location, from, to, chocotime.Location.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get heatmap cells: %w", err)
+	}
+
+	heatmap := &Heatmap{}
+	for _, c := range cells {
+		if c.Weekday < 1 || c.Weekday > 7 || c.Hour < 0 || c.Hour > 23 {
+			return nil, fmt.Errorf("heatmap cell out of range: weekday %d, hour %d", c.Weekday, c.Hour)
+		}
+		heatmap.Payments[c.Weekday-1][c.Hour] = c.Payments
+		heatmap.Revenue[c.Weekday-1][c.Hour] = c.Revenue
+	}
+
+	return heatmap, nil
+}
+
+// StaffActivity sums the transactions per staff member at the location by day, week or month
+// between from and to inclusive.
+func (s *ReportService) StaffActivity(
+	ctx context.Context,
+	location string,
+	from, to domain.Date,
+	groupBy string,
+) ([]domain.StaffActivity, error) {
+	if err := validateGroupBy(groupBy); err != nil {
+		return nil, err
+	}
+
+	activity, err := s.reportRepo.StaffActivity(ctx, location, from, to, chocotime.Location.String(), groupBy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get staff activity: %w", err)
+	}
+
+	return activity, nil
+}
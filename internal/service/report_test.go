@@ -0,0 +1,67 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ibookerke/choco_parser_go/internal/domain"
+)
+
+func TestCohortRows(t *testing.T) {
+	t.Parallel()
+
+	month := func(year int, m time.Month) domain.Date { return domain.NewDate(year, m, 1) }
+
+	tests := map[string]struct {
+		cells []domain.CohortCell
+		want  []CohortRow
+	}{
+		"gaps": {
+			cells: []domain.CohortCell{
+				{Cohort: month(2023, time.December), Offset: 0, Customers: 2},
+				{Cohort: month(2023, time.December), Offset: 1, Customers: 2},
+				{Cohort: month(2024, time.January), Offset: 0, Customers: 10},
+				{Cohort: month(2024, time.January), Offset: 2, Customers: 5},
+				// no customers started in February
+				{Cohort: month(2024, time.March), Offset: 0, Customers: 4},
+				{Cohort: month(2024, time.March), Offset: 1, Customers: 1},
+			},
+			want: []CohortRow{
+				{Cohort: month(2023, time.December), Customers: 2, Retention: []float64{100, 100, 0, 0, 0}},
+				{Cohort: month(2024, time.January), Customers: 10, Retention: []float64{100, 0, 50, 0}},
+				{Cohort: month(2024, time.March), Customers: 4, Retention: []float64{100, 25}},
+			},
+		},
+		"no returns": {
+			cells: []domain.CohortCell{
+				{Cohort: month(2024, time.May), Offset: 0, Customers: 3},
+				{Cohort: month(2024, time.June), Offset: 0, Customers: 4},
+				{Cohort: month(2024, time.June), Offset: 2, Customers: 1},
+			},
+			want: []CohortRow{
+				{Cohort: month(2024, time.May), Customers: 3, Retention: []float64{100, 0, 0, 0}},
+				{Cohort: month(2024, time.June), Customers: 4, Retention: []float64{100, 0, 25}},
+			},
+		},
+		"single cohort without returns": {
+			cells: []domain.CohortCell{
+				{Cohort: month(2024, time.May), Offset: 0, Customers: 3},
+			},
+			want: []CohortRow{
+				{Cohort: month(2024, time.May), Customers: 3, Retention: []float64{100}},
+			},
+		},
+		"empty": {},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, cohortRows(tt.cells))
+		})
+	}
+}
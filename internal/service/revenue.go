@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ibookerke/choco_parser_go/internal/domain"
+	"github.com/ibookerke/choco_parser_go/internal/pkg/chocotime"
+	"github.com/ibookerke/choco_parser_go/internal/pkg/trm"
+)
+
+type RevenueService struct {
+	revenueRepo domain.RevenueRepository
+	trm         trm.Manager
+}
+
+func NewRevenueService(
+	revenueRepo domain.RevenueRepository,
+	trm trm.Manager,
+) *RevenueService {
+	return &RevenueService{
+		revenueRepo: revenueRepo,
+		trm:         trm,
+	}
+}
+
+// RefreshDailyRevenue recomputes the daily summary for the synced range and the days before it
+// whose payments refunds linked on this sync may reverse: a refund is retried for RefundRetryWindow
+// and reverses a payment made up to RefundWindow before it.
+func (s *RevenueService) RefreshDailyRevenue(ctx context.Context, synced chocotime.DateRange) error {
+	from := domain.DateOf(synced.From.Add(-domain.RefundRetryWindow - domain.RefundWindow).In(chocotime.Location))
+
+	err := s.trm.Do(ctx, func(ctx context.Context) error {
+		return s.revenueRepo.RefreshDailyRevenue(ctx, from, chocotime.Location.String())
+	})
+	if err != nil {
+		return fmt.Errorf("failed to refresh daily revenue: %w", err)
+	}
+
+	return nil
+}
+
+// Revenue sums daily revenue per location over days, weeks or months between from and to inclusive.
+func (s *RevenueService) Revenue(ctx context.Context, from, to domain.Date, groupBy string) ([]domain.RevenueRow, error) {
+	if err := validateGroupBy(groupBy); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.revenueRepo.Revenue(ctx, from, to, groupBy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get revenue: %w", err)
+	}
+
+	return rows, nil
+}
+
+func validateGroupBy(groupBy string) error {
+	switch groupBy {
+	case domain.GroupByDay, domain.GroupByWeek, domain.GroupByMonth:
+		return nil
+	default:
+		return fmt.Errorf("unknown grouping %q, expected day, week or month", groupBy)
+	}
+}
@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ibookerke/choco_parser_go/internal/domain"
+	"github.com/ibookerke/choco_parser_go/internal/pkg/chocotime"
+	"github.com/ibookerke/choco_parser_go/internal/pkg/segfilter"
+	"github.com/ibookerke/choco_parser_go/internal/pkg/trm"
+)
+
+type SavedSegmentService struct {
+	savedSegmentRepo domain.SavedSegmentRepository
+	trm              trm.Manager
+}
+
+func NewSavedSegmentService(
+	savedSegmentRepo domain.SavedSegmentRepository,
+	trm trm.Manager,
+) *SavedSegmentService {
+	return &SavedSegmentService{
+		savedSegmentRepo: savedSegmentRepo,
+		trm:              trm,
+	}
+}
+
+// Save stores the filter under the name, replacing the filter of an existing segment.
+// The filter is checked first so only valid filters get saved, a changed filter
+// resets the members and the next evaluation sets a new baseline without events.
+func (s *SavedSegmentService) Save(ctx context.Context, name, filter string, now time.Time) (*domain.SavedSegment, error) {
+	if _, err := segfilter.Parse(filter); err != nil {
+		return nil, fmt.Errorf("invalid filter: %w", err)
+	}
+
+	var segment *domain.SavedSegment
+	err := s.trm.Do(ctx, func(ctx context.Context) error {
+		var err error
+		if segment, err = s.savedSegmentRepo.Save(ctx, name, filter, now); err != nil {
+			return fmt.Errorf("failed to save segment: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return segment, nil
+}
+
+func (s *SavedSegmentService) List(ctx context.Context) ([]domain.SavedSegment, error) {
+	segments, err := s.savedSegmentRepo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list segments: %w", err)
+	}
+
+	return segments, nil
+}
+
+// SegmentEvaluation is a saved segment after an evaluation with the users that entered and left it.
+type SegmentEvaluation struct {
+	Segment domain.SavedSegment
+	Entered []int64
+	Left    []int64
+}
+
+// Eval matches the customers against the filter of the saved segment at now, stores them as its members
+// and records who entered and left it since the previous evaluation. The first evaluation only sets
+// the baseline, it records no events.
+func (s *SavedSegmentService) Eval(ctx context.Context, name string, now time.Time) (SegmentEvaluation, error) {
+	segment, err := s.savedSegmentRepo.FindByName(ctx, name)
+	if err != nil {
+		return SegmentEvaluation{}, fmt.Errorf("failed to find segment: %w", err)
+	}
+
+	filter, err := segfilter.Parse(segment.Filter)
+	if err != nil {
+		return SegmentEvaluation{}, fmt.Errorf("invalid filter of segment %s: %w", name, err)
+	}
+
+	var eval SegmentEvaluation
+	err = s.trm.Do(ctx, func(ctx context.Context) error {
+		previous, err := s.savedSegmentRepo.Members(ctx, segment.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get segment members: %w", err)
+		}
+
+		userIDs, err := s.savedSegmentRepo.Match(ctx, filter, now)
+		if err != nil {
+			return fmt.Errorf("failed to match segment: %w", err)
+		}
+
+		if !segment.EvaluatedAt.IsZero() {
+			eval.Entered, eval.Left = domain.DiffSegmentMembers(previous, userIDs)
+
+			events := make([]domain.SegmentMemberEvent, 0, len(eval.Entered)+len(eval.Left))
+			for _, id := range eval.Entered {
+				events = append(events, domain.SegmentMemberEvent{SegmentID: segment.ID, UserID: id, Event: domain.SegmentMemberEntered, At: now})
+			}
+			for _, id := range eval.Left {
+				events = append(events, domain.SegmentMemberEvent{SegmentID: segment.ID, UserID: id, Event: domain.SegmentMemberLeft, At: now})
+			}
+
+			if err := s.savedSegmentRepo.RecordMemberEvents(ctx, events); err != nil {
+				return fmt.Errorf("failed to record segment member events: %w", err)
+			}
+		}
+
+		if err := s.savedSegmentRepo.ReplaceMembers(ctx, segment.ID, userIDs, now); err != nil {
+			return fmt.Errorf("failed to store segment members: %w", err)
+		}
+
+		segment.EvaluatedAt = now
+		segment.Members = len(userIDs)
+
+		return nil
+	})
+	if err != nil {
+		return SegmentEvaluation{}, err
+	}
+
+	eval.Segment = *segment
+
+	return eval, nil
+}
+
+// Changes lists who entered or left the saved segment between the Almaty days, both when event is empty.
+func (s *SavedSegmentService) Changes(
+	ctx context.Context,
+	name, event string,
+	from, to domain.Date,
+) ([]domain.SegmentMemberChange, error) {
+	if event != "" {
+		if err := validateMemberEvent(event); err != nil {
+			return nil, err
+		}
+	}
+
+	segment, err := s.savedSegmentRepo.FindByName(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find segment: %w", err)
+	}
+
+	changes, err := s.savedSegmentRepo.MemberChanges(ctx, segment.ID, event, from, to, chocotime.Location.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get segment member changes: %w", err)
+	}
+
+	return changes, nil
+}
+
+// ChangePhones lists the normalized phones of the customers that entered or left the saved segment
+// on its latest evaluation.
+func (s *SavedSegmentService) ChangePhones(ctx context.Context, name, event string) ([]string, error) {
+	if err := validateMemberEvent(event); err != nil {
+		return nil, err
+	}
+
+	segment, err := s.savedSegmentRepo.FindByName(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find segment: %w", err)
+	}
+	if segment.EvaluatedAt.IsZero() {
+		return nil, fmt.Errorf("segment %s was never evaluated, see segments eval", name)
+	}
+
+	phones, err := s.savedSegmentRepo.ChangePhones(ctx, segment.ID, event, segment.EvaluatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get segment change phones: %w", err)
+	}
+
+	return phones, nil
+}
+
+func validateMemberEvent(event string) error {
+	switch event {
+	case domain.SegmentMemberEntered, domain.SegmentMemberLeft:
+		return nil
+	default:
+		return fmt.Errorf("unknown segment event %q, expected entered or left", event)
+	}
+}
+
+// FilterPhones lists the normalized phones of the customers matching the filter at now.
+func (s *SavedSegmentService) FilterPhones(ctx context.Context, filter string, now time.Time) ([]string, error) {
+	f, err := segfilter.Parse(filter)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter: %w", err)
+	}
+
+	phones, err := s.savedSegmentRepo.MatchPhones(ctx, f, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to match filter phones: %w", err)
+	}
+
+	return phones, nil
+}
+
+// SavedPhones lists the normalized phones of the customers matching the saved segment's filter at now,
+// without storing them as its members.
+func (s *SavedSegmentService) SavedPhones(ctx context.Context, name string, now time.Time) ([]string, error) {
+	segment, err := s.savedSegmentRepo.FindByName(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find segment: %w", err)
+	}
+
+	return s.FilterPhones(ctx, segment.Filter, now)
+}
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ibookerke/choco_parser_go/internal/domain"
+	"github.com/ibookerke/choco_parser_go/internal/pkg/chocotime"
+	"github.com/ibookerke/choco_parser_go/internal/pkg/rfm"
+	"github.com/ibookerke/choco_parser_go/internal/pkg/trm"
+)
+
+type SegmentService struct {
+	segmentRepo domain.CustomerSegmentRepository
+	branchRepo  domain.BranchRepository
+	trm         trm.Manager
+}
+
+func NewSegmentService(
+	segmentRepo domain.CustomerSegmentRepository,
+	branchRepo domain.BranchRepository,
+	trm trm.Manager,
+) *SegmentService {
+	return &SegmentService{
+		segmentRepo: segmentRepo,
+		branchRepo:  branchRepo,
+		trm:         trm,
+	}
+}
+
+// SegmentSummary is the size and revenue of one RFM segment.
+type SegmentSummary struct {
+	Segment       string
+	Customers     int
+	CustomerShare float64
+	Revenue       domain.Money
+	RevenueShare  float64
+}
+
+// RFMRun is the result of one segmentation run.
+type RFMRun struct {
+	RunDate  domain.Date
+	Company  string
+	Source   string
+	Segments []domain.CustomerSegment
+	Summary  []SegmentSummary
+}
+
+// RunRFM scores the customers of the company from its payments, falling back to the
+// company_customers stats when no payments were synced for it, and stores the run.
+func (s *SegmentService) RunRFM(ctx context.Context, company string, now time.Time) (RFMRun, error) {
+	branchIds, err := companyBranchIds(ctx, s.branchRepo, company)
+	if err != nil {
+		return RFMRun{}, err
+	}
+
+	source := domain.SegmentSourcePayments
+	stats, err := s.segmentRepo.RFMStatsFromPayments(ctx, branchIds)
+	if err != nil {
+		return RFMRun{}, fmt.Errorf("failed to get rfm stats from payments: %w", err)
+	}
+
+	if len(stats) == 0 {
+		source = domain.SegmentSourceCompanyCustomers
+		stats, err = s.segmentRepo.RFMStatsFromCompanyCustomers(ctx, company)
+		if err != nil {
+			return RFMRun{}, fmt.Errorf("failed to get rfm stats from company customers: %w", err)
+		}
+	}
+
+	today := chocotime.StartOfDay(now)
+	run := RFMRun{
+		RunDate: domain.DateOf(today),
+		Company: company,
+		Source:  source,
+	}
+
+	inputs := make([]rfm.Input, len(stats))
+	for i, st := range stats {
+		inputs[i] = rfm.Input{
+			RecencyDays: daysBetween(st.LastVisit, today),
+			Frequency:   st.Frequency,
+			Monetary:    int64(st.Monetary),
+		}
+	}
+
+	for i, score := range rfm.ScoreAll(inputs) {
+		run.Segments = append(run.Segments, domain.CustomerSegment{
+			RunDate:     run.RunDate,
+			Company:     company,
+			UserID:      stats[i].UserID,
+			RecencyDays: inputs[i].RecencyDays,
+			Frequency:   stats[i].Frequency,
+			Monetary:    stats[i].Monetary,
+			RScore:      score.R,
+			FScore:      score.F,
+			MScore:      score.M,
+			Segment:     rfm.SegmentOf(score),
+			Source:      source,
+		})
+	}
+
+	err = s.trm.Do(ctx, func(ctx context.Context) error {
+		return s.segmentRepo.ReplaceRun(ctx, run.RunDate, company, run.Segments)
+	})
+	if err != nil {
+		return RFMRun{}, fmt.Errorf("failed to store segments: %w", err)
+	}
+
+	run.Summary = summarizeSegments(run.Segments)
+
+	return run, nil
+}
+
+// AudiencePhones lists the normalized phones of the customers in the RFM segment,
+// taken from the latest run of the company or of every company when company is empty.
+func (s *SegmentService) AudiencePhones(ctx context.Context, company, segment string) ([]string, error) {
+	phones, err := s.segmentRepo.SegmentPhones(ctx, company, segment)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get segment phones: %w", err)
+	}
+
+	return phones, nil
+}
+
+func summarizeSegments(segments []domain.CustomerSegment) []SegmentSummary {
+	var (
+		order        []string
+		bySegment    = make(map[string]*SegmentSummary)
+		totalRevenue domain.Money
+	)
+
+	for _, seg := range segments {
+		summary, ok := bySegment[seg.Segment]
+		if !ok {
+			summary = &SegmentSummary{Segment: seg.Segment}
+			bySegment[seg.Segment] = summary
+			order = append(order, seg.Segment)
+		}
+		summary.Customers++
+		summary.Revenue += seg.Monetary
+		totalRevenue += seg.Monetary
+	}
+
+	summaries := make([]SegmentSummary, 0, len(order))
+	for _, name := range order {
+		summary := bySegment[name]
+		summary.CustomerShare = share(int64(summary.Customers), int64(len(segments)))
+		summary.RevenueShare = share(int64(summary.Revenue), int64(totalRevenue))
+		summaries = append(summaries, *summary)
+	}
+
+	sort.SliceStable(summaries, func(i, j int) bool { return summaries[i].Revenue > summaries[j].Revenue })
+
+	return summaries
+}
+
+// daysBetween counts whole Almaty days from t to the day start, never negative.
+func daysBetween(t, dayStart time.Time) int {
+	days := int(dayStart.Sub(chocotime.StartOfDay(t)).Hours() / 24)
+	if days < 0 {
+		return 0
+	}
+
+	return days
+}
+
+// share is part/total in percent, 0 when total is 0.
+func share(part, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+
+	return float64(part) * 100 / float64(total)
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -100,7 +101,19 @@ func sendChocoRequest(
 	return body, nil
 }
 
+// ErrTokenRefresh marks failures to get a new Choco access token, so callers can tell them from other sync errors.
+var ErrTokenRefresh = errors.New("access token refresh failed")
+
 func fetchNewAccessToken(ctx context.Context, authRepo domain.AuthRepository, client *http.Client, cfg config.Choco) (string, error) {
+	token, err := requestNewAccessToken(ctx, authRepo, client, cfg)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrTokenRefresh, err)
+	}
+
+	return token, nil
+}
+
+func requestNewAccessToken(ctx context.Context, authRepo domain.AuthRepository, client *http.Client, cfg config.Choco) (string, error) {
 	auth, err := authRepo.GetAuthByClientId(ctx, cfg.ClientId)
 	if err != nil {
 		return "", fmt.Errorf("failed to get auth: %w", err)
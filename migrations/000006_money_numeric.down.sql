@@ -0,0 +1,7 @@
+ALTER TABLE company_customers
+    ALTER COLUMN turnover TYPE FLOAT USING turnover::FLOAT,
+    ALTER COLUMN average_bill TYPE FLOAT USING average_bill::FLOAT;
+
+ALTER TABLE payments
+    ALTER COLUMN amount TYPE BIGINT USING trunc(amount)::BIGINT,
+    ALTER COLUMN discount_amount TYPE BIGINT USING trunc(discount_amount)::BIGINT;
@@ -0,0 +1,7 @@
+ALTER TABLE payments
+    ALTER COLUMN amount TYPE NUMERIC(14, 2) USING amount::NUMERIC(14, 2),
+    ALTER COLUMN discount_amount TYPE NUMERIC(14, 2) USING discount_amount::NUMERIC(14, 2);
+
+ALTER TABLE company_customers
+    ALTER COLUMN turnover TYPE NUMERIC(14, 2) USING round(turnover::NUMERIC, 2),
+    ALTER COLUMN average_bill TYPE NUMERIC(14, 2) USING round(average_bill::NUMERIC, 2);
@@ -0,0 +1,7 @@
+ALTER TABLE customers DROP COLUMN IF EXISTS birthday_date;
+
+ALTER TABLE company_customers
+    ALTER COLUMN last_visit_date TYPE TIMESTAMP USING last_visit_date AT TIME ZONE 'Asia/Almaty';
+
+ALTER TABLE payments
+    ALTER COLUMN created_at TYPE TIMESTAMP USING created_at AT TIME ZONE 'Asia/Almaty';
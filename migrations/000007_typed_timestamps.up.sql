@@ -0,0 +1,9 @@
+-- existing values were stored as Almaty wall clock strings
+ALTER TABLE payments
+    ALTER COLUMN created_at TYPE TIMESTAMPTZ USING created_at AT TIME ZONE 'Asia/Almaty';
+
+ALTER TABLE company_customers
+    ALTER COLUMN last_visit_date TYPE TIMESTAMPTZ USING last_visit_date AT TIME ZONE 'Asia/Almaty';
+
+-- filled by the customers_birthday_to_date data migration, swapped in by 000008
+ALTER TABLE customers ADD COLUMN birthday_date DATE NULL;
@@ -0,0 +1,3 @@
+ALTER TABLE customers RENAME COLUMN birthday TO birthday_date;
+ALTER TABLE customers ADD COLUMN birthday VARCHAR(50);
+UPDATE customers SET birthday = to_char(birthday_date, 'YYYY-MM-DD') WHERE birthday_date IS NOT NULL;
@@ -0,0 +1,2 @@
+ALTER TABLE customers DROP COLUMN birthday;
+ALTER TABLE customers RENAME COLUMN birthday_date TO birthday;
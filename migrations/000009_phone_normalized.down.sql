@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS company_customers_phone_normalized_idx;
+DROP INDEX IF EXISTS customers_phone_normalized_idx;
+
+ALTER TABLE company_customers DROP COLUMN IF EXISTS phone_normalized;
+ALTER TABLE customers DROP COLUMN IF EXISTS phone_normalized;
@@ -0,0 +1,6 @@
+-- filled at ingest and backfilled by the phone_normalized data migration
+ALTER TABLE customers ADD COLUMN phone_normalized TEXT NULL;
+ALTER TABLE company_customers ADD COLUMN phone_normalized TEXT NULL;
+
+CREATE INDEX customers_phone_normalized_idx ON customers (phone_normalized);
+CREATE INDEX company_customers_phone_normalized_idx ON company_customers (phone_normalized);
@@ -0,0 +1,7 @@
+DROP VIEW IF EXISTS customer_profiles;
+DROP VIEW IF EXISTS customer_company_stats;
+
+DROP TABLE IF EXISTS payment_reviews;
+
+DROP INDEX IF EXISTS payments_user_id_created_at_idx;
+ALTER TABLE payments DROP COLUMN IF EXISTS user_id;
@@ -0,0 +1,58 @@
+ALTER TABLE payments ADD COLUMN user_id BIGINT NULL;
+CREATE INDEX payments_user_id_created_at_idx ON payments (user_id, created_at DESC);
+
+CREATE TABLE payment_reviews (
+    payment_id BIGINT PRIMARY KEY,
+    user_id BIGINT NULL,
+    rating INT NULL,
+    comment TEXT NULL,
+    created_at TIMESTAMPTZ NULL,
+    raw JSONB NOT NULL
+);
+CREATE INDEX payment_reviews_user_id_idx ON payment_reviews (user_id);
+
+-- company_customers gets a new row on every sync, only the latest one per company and user counts
+CREATE VIEW customer_company_stats AS
+SELECT DISTINCT ON (company, user_id)
+    user_id, company, full_name, phone, phone_normalized, turnover, visits_count, average_bill, last_visit_date
+FROM company_customers
+WHERE user_id IS NOT NULL
+ORDER BY company, user_id, id DESC;
+
+CREATE VIEW customer_profiles AS
+WITH customer_rows AS (
+    SELECT DISTINCT ON (user_id)
+        user_id::BIGINT AS user_id, full_name, phone, phone_normalized, birthday, orders_count
+    FROM customers
+    ORDER BY user_id, id DESC
+), company_totals AS (
+    SELECT
+        user_id,
+        COUNT(*) AS companies_count,
+        SUM(turnover) AS turnover,
+        SUM(visits_count) AS visits_count,
+        MAX(last_visit_date) AS last_visit_date,
+        (array_agg(full_name ORDER BY last_visit_date DESC NULLS LAST))[1] AS full_name,
+        (array_agg(phone ORDER BY last_visit_date DESC NULLS LAST))[1] AS phone,
+        (array_agg(phone_normalized ORDER BY last_visit_date DESC NULLS LAST))[1] AS phone_normalized
+    FROM customer_company_stats
+    GROUP BY user_id
+), users AS (
+    SELECT user_id FROM customer_rows
+    UNION
+    SELECT user_id FROM company_totals
+)
+SELECT
+    u.user_id,
+    COALESCE(c.full_name, t.full_name, '') AS full_name,
+    COALESCE(c.phone, t.phone, '') AS phone,
+    COALESCE(c.phone_normalized, t.phone_normalized, '') AS phone_normalized,
+    c.birthday,
+    COALESCE(c.orders_count, 0) AS orders_count,
+    COALESCE(t.companies_count, 0) AS companies_count,
+    COALESCE(t.turnover, 0) AS turnover,
+    COALESCE(t.visits_count, 0) AS visits_count,
+    t.last_visit_date
+FROM users u
+LEFT JOIN customer_rows c ON c.user_id = u.user_id
+LEFT JOIN company_totals t ON t.user_id = u.user_id;
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS payments_location_id_created_at_idx;
+DROP INDEX IF EXISTS payments_branch_id_created_at_idx;
+
+ALTER TABLE payments
+    DROP COLUMN IF EXISTS location_id,
+    DROP COLUMN IF EXISTS branch_id;
@@ -0,0 +1,44 @@
+ALTER TABLE payments
+    ADD COLUMN branch_id BIGINT NULL,
+    ADD COLUMN location_id UUID NULL;
+
+CREATE INDEX payments_branch_id_created_at_idx ON payments (branch_id, created_at);
+CREATE INDEX payments_location_id_created_at_idx ON payments (location_id, created_at);
+
+-- attribute already stored payments the same way the sync does, see branchResolver: by partner and
+-- location title compared case-insensitively with whitespace collapsed, preferring the main terminal
+-- of a location, and by partner alone when the partner has a single location
+CREATE TEMPORARY TABLE payment_locations AS
+SELECT DISTINCT ON (partner_id, location_key)
+    id, partner_id, location_id, location_key
+FROM (
+    SELECT id, partner_id, location_id, type_name,
+        lower(btrim(regexp_replace(COALESCE(location_name, ''), '\s+', ' ', 'g'))) AS location_key
+    FROM branches
+) b
+ORDER BY partner_id, location_key, (type_name = 'main') DESC, id;
+
+UPDATE payments p
+SET branch_id = l.id, location_id = l.location_id
+FROM payment_locations l
+WHERE p.branch_id IS NULL
+  AND l.partner_id = p.location_partner_id
+  AND l.location_key = lower(btrim(regexp_replace(COALESCE(p.location_title, ''), '\s+', ' ', 'g')));
+
+UPDATE payments p
+SET branch_id = l.id, location_id = l.location_id
+FROM (
+    SELECT DISTINCT ON (partner_id) id, partner_id, location_id
+    FROM payment_locations
+    WHERE partner_id IN (
+        SELECT partner_id
+        FROM payment_locations
+        GROUP BY partner_id
+        HAVING COUNT(DISTINCT COALESCE(location_id::TEXT, '')) = 1
+    )
+    ORDER BY partner_id, id
+) l
+WHERE p.branch_id IS NULL
+  AND l.partner_id = p.location_partner_id;
+
+DROP TABLE payment_locations;
@@ -0,0 +1,9 @@
+-- audit of right-to-erasure requests, also consulted by the syncs to skip erased users
+CREATE TABLE privacy_erasures (
+    user_id BIGINT PRIMARY KEY,
+    erased_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    customers_deleted BIGINT NOT NULL DEFAULT 0,
+    company_customers_deleted BIGINT NOT NULL DEFAULT 0,
+    payments_anonymized BIGINT NOT NULL DEFAULT 0,
+    reviews_deleted BIGINT NOT NULL DEFAULT 0
+);
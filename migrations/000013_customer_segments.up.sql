@@ -0,0 +1,16 @@
+CREATE TABLE customer_segments (
+    id SERIAL PRIMARY KEY,
+    run_date DATE NOT NULL,
+    company TEXT NOT NULL,
+    user_id BIGINT NOT NULL,
+    recency_days INT NOT NULL,
+    frequency BIGINT NOT NULL,
+    monetary NUMERIC(14, 2) NOT NULL,
+    r_score SMALLINT NOT NULL,
+    f_score SMALLINT NOT NULL,
+    m_score SMALLINT NOT NULL,
+    segment TEXT NOT NULL,
+    source TEXT NOT NULL,
+    UNIQUE (run_date, company, user_id)
+);
+CREATE INDEX customer_segments_company_segment_idx ON customer_segments (company, segment, run_date);
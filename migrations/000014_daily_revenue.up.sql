@@ -0,0 +1,53 @@
+-- per day summary of payments by location, days are Almaty calendar days. Payments attributed to a
+-- location are summed under its location_id and the title of its main terminal, the rest under their
+-- partner and location title.
+CREATE TABLE daily_revenue (
+    day DATE NOT NULL,
+    location_id UUID NULL,
+    location_partner_id UUID NULL,
+    location_title TEXT NOT NULL,
+    gross_amount NUMERIC(14, 2) NOT NULL DEFAULT 0,
+    discount_amount NUMERIC(14, 2) NOT NULL DEFAULT 0,
+    refund_amount NUMERIC(14, 2) NOT NULL DEFAULT 0,
+    net_amount NUMERIC(14, 2) NOT NULL DEFAULT 0,
+    payments_count BIGINT NOT NULL DEFAULT 0,
+    refunds_count BIGINT NOT NULL DEFAULT 0,
+    refreshed_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE UNIQUE INDEX daily_revenue_day_location_idx ON daily_revenue (
+    day,
+    COALESCE(location_id, '00000000-0000-0000-0000-000000000000'),
+    COALESCE(location_partner_id, '00000000-0000-0000-0000-000000000000'),
+    location_title
+);
+
+INSERT INTO daily_revenue
+    (day, location_id, location_partner_id, location_title, gross_amount, discount_amount, refund_amount, net_amount,
+    payments_count, refunds_count)
+SELECT
+    p.day,
+    p.location_id,
+    (array_agg(p.location_partner_id))[1],
+    COALESCE(MAX(l.location_name), (array_agg(p.location_title ORDER BY p.created_at DESC))[1], ''),
+    COALESCE(SUM(p.amount) FILTER (WHERE p.type = 'pay'), 0),
+    COALESCE(SUM(p.discount_amount) FILTER (WHERE p.type = 'pay'), 0),
+    COALESCE(SUM(ABS(p.amount)) FILTER (WHERE p.type = 'refund'), 0),
+    COALESCE(SUM(p.amount) FILTER (WHERE p.type = 'pay'), 0) - COALESCE(SUM(ABS(p.amount)) FILTER (WHERE p.type = 'refund'), 0),
+    COUNT(*) FILTER (WHERE p.type = 'pay'),
+    COUNT(*) FILTER (WHERE p.type = 'refund')
+FROM (
+    SELECT *, (created_at AT TIME ZONE 'Asia/Almaty')::DATE AS day
+    FROM payments
+    WHERE created_at IS NOT NULL
+) p
+LEFT JOIN (
+    SELECT DISTINCT ON (location_id) location_id, NULLIF(location_name, '') AS location_name
+    FROM branches
+    WHERE location_id IS NOT NULL
+    ORDER BY location_id, (type_name = 'main') DESC, id
+) l ON l.location_id = p.location_id
+GROUP BY
+    p.day,
+    p.location_id,
+    CASE WHEN p.location_id IS NULL THEN p.location_partner_id END,
+    CASE WHEN p.location_id IS NULL THEN COALESCE(p.location_title, '') END;
@@ -0,0 +1,13 @@
+-- every company sync keeps a copy of the customer stats to compare runs with
+CREATE TABLE company_customer_snapshots (
+    id BIGSERIAL PRIMARY KEY,
+    company TEXT NOT NULL,
+    taken_at TIMESTAMPTZ NOT NULL,
+    user_id BIGINT NOT NULL,
+    turnover NUMERIC(14, 2) NULL,
+    visits_count BIGINT NULL,
+    average_bill NUMERIC(14, 2) NULL,
+    last_visit_date TIMESTAMPTZ NULL
+);
+CREATE INDEX company_customer_snapshots_company_taken_at_idx ON company_customer_snapshots (company, taken_at);
+CREATE INDEX company_customer_snapshots_user_id_idx ON company_customer_snapshots (user_id);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS payments_unlinked_refunds_idx;
+DROP INDEX IF EXISTS payments_refund_of_idx;
+
+ALTER TABLE payments
+    DROP COLUMN IF EXISTS refunded_amount,
+    DROP COLUMN IF EXISTS refund_of;
@@ -0,0 +1,7 @@
+-- refunds point to the payment they reverse, the payment keeps the sum refunded so far
+ALTER TABLE payments
+    ADD COLUMN refund_of BIGINT NULL,
+    ADD COLUMN refunded_amount NUMERIC(14, 2) NOT NULL DEFAULT 0;
+
+CREATE INDEX payments_refund_of_idx ON payments (refund_of);
+CREATE INDEX payments_unlinked_refunds_idx ON payments (created_at) WHERE type = 'refund' AND refund_of IS NULL;
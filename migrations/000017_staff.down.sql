@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS payments_created_by_created_at_idx;
+DROP TABLE IF EXISTS staff;
@@ -0,0 +1,15 @@
+-- the API exposes staff only as payments.created_by, names are filled in by hand
+CREATE TABLE staff (
+    id BIGINT PRIMARY KEY,
+    name TEXT NULL,
+    first_seen_at TIMESTAMPTZ NOT NULL,
+    last_seen_at TIMESTAMPTZ NOT NULL
+);
+
+INSERT INTO staff (id, first_seen_at, last_seen_at)
+SELECT created_by, MIN(created_at), MAX(created_at)
+FROM payments
+WHERE created_by IS NOT NULL AND created_by <> 0
+GROUP BY created_by;
+
+CREATE INDEX payments_created_by_created_at_idx ON payments (created_by, created_at);
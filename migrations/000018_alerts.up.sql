@@ -0,0 +1,11 @@
+CREATE TABLE alerts (
+    id BIGSERIAL PRIMARY KEY,
+    rule TEXT NOT NULL,
+    kind TEXT NOT NULL,
+    location TEXT NOT NULL,
+    key TEXT NOT NULL,
+    message TEXT NOT NULL,
+    triggered_at TIMESTAMPTZ NOT NULL,
+    UNIQUE (rule, location, key)
+);
+CREATE INDEX alerts_triggered_at_idx ON alerts (triggered_at);
@@ -0,0 +1,13 @@
+-- every forecast run keeps its predictions so they can be compared with daily_revenue later
+CREATE TABLE forecasts (
+    id BIGSERIAL PRIMARY KEY,
+    location TEXT NOT NULL,
+    made_on DATE NOT NULL,
+    day DATE NOT NULL,
+    predicted NUMERIC(14, 2) NOT NULL,
+    lower_bound NUMERIC(14, 2) NOT NULL,
+    upper_bound NUMERIC(14, 2) NOT NULL,
+    model TEXT NOT NULL,
+    UNIQUE (location, made_on, day)
+);
+CREATE INDEX forecasts_location_day_idx ON forecasts (location, day);
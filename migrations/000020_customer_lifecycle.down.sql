@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS customer_lifecycle_events;
+DROP TABLE IF EXISTS customer_lifecycle;
@@ -0,0 +1,15 @@
+CREATE TABLE customer_lifecycle (
+    user_id BIGINT PRIMARY KEY,
+    stage TEXT NOT NULL,
+    since TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE customer_lifecycle_events (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    from_stage TEXT NULL,
+    to_stage TEXT NOT NULL,
+    changed_at TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX customer_lifecycle_events_changed_at_idx ON customer_lifecycle_events (changed_at);
+CREATE INDEX customer_lifecycle_events_user_id_idx ON customer_lifecycle_events (user_id);
@@ -0,0 +1,17 @@
+CREATE TABLE segments (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    filter TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL,
+    evaluated_at TIMESTAMPTZ NULL,
+    members_count INT NOT NULL DEFAULT 0
+);
+
+-- membership of the latest evaluation of each segment
+CREATE TABLE segment_members (
+    segment_id INT NOT NULL,
+    user_id BIGINT NOT NULL,
+    PRIMARY KEY (segment_id, user_id)
+);
+CREATE INDEX segment_members_user_id_idx ON segment_members (user_id);
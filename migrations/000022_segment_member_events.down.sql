@@ -0,0 +1 @@
+DROP TABLE IF EXISTS segment_member_events;
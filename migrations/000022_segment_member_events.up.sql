@@ -0,0 +1,9 @@
+CREATE TABLE segment_member_events (
+    id BIGSERIAL PRIMARY KEY,
+    segment_id INT NOT NULL,
+    user_id BIGINT NOT NULL,
+    event TEXT NOT NULL,
+    happened_at TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX segment_member_events_segment_id_happened_at_idx ON segment_member_events (segment_id, happened_at);
+CREATE INDEX segment_member_events_user_id_idx ON segment_member_events (user_id);
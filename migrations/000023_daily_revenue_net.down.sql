@@ -0,0 +1 @@
+-- daily_revenue is derived from payments, the next refresh rebuilds the days it covers
@@ -0,0 +1,41 @@
+-- recompute daily_revenue under the net revenue rule, see domain.Payment.NetAmount: a payment counts its
+-- amount less the refunds matched to it on its own day, an unmatched refund is subtracted on its own day
+DELETE FROM daily_revenue;
+
+INSERT INTO daily_revenue
+    (day, location_id, location_partner_id, location_title, gross_amount, discount_amount, refund_amount, net_amount,
+    payments_count, refunds_count)
+SELECT
+    p.day,
+    p.location_id,
+    (array_agg(p.location_partner_id))[1],
+    COALESCE(MAX(l.location_name), (array_agg(p.location_title ORDER BY p.created_at DESC))[1], ''),
+    COALESCE(SUM(p.amount) FILTER (WHERE p.type = 'pay'), 0),
+    COALESCE(SUM(p.discount_amount) FILTER (WHERE p.type = 'pay'), 0),
+    COALESCE(SUM(p.amount) FILTER (WHERE p.type = 'pay'), 0) - COALESCE(SUM(p.net_amount), 0),
+    COALESCE(SUM(p.net_amount), 0),
+    COUNT(*) FILTER (WHERE p.type = 'pay'),
+    COUNT(*) FILTER (WHERE p.type = 'refund')
+FROM (
+    SELECT
+        *,
+        (created_at AT TIME ZONE 'Asia/Almaty')::DATE AS day,
+        CASE
+            WHEN type = 'pay' THEN amount - refunded_amount
+            WHEN refund_of IS NULL THEN -ABS(amount)
+            ELSE 0
+        END AS net_amount
+    FROM payments
+    WHERE created_at IS NOT NULL
+) p
+LEFT JOIN (
+    SELECT DISTINCT ON (location_id) location_id, NULLIF(location_name, '') AS location_name
+    FROM branches
+    WHERE location_id IS NOT NULL
+    ORDER BY location_id, (type_name = 'main') DESC, id
+) l ON l.location_id = p.location_id
+GROUP BY
+    p.day,
+    p.location_id,
+    CASE WHEN p.location_id IS NULL THEN p.location_partner_id END,
+    CASE WHEN p.location_id IS NULL THEN COALESCE(p.location_title, '') END;
@@ -0,0 +1,4 @@
+CREATE TABLE alert_evaluations (
+    rule TEXT PRIMARY KEY,
+    evaluated_at TIMESTAMPTZ NOT NULL
+);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS alerts_unnotified_idx;
+ALTER TABLE alerts DROP COLUMN IF EXISTS notified_at;
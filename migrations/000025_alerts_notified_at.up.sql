@@ -0,0 +1,4 @@
+ALTER TABLE alerts ADD COLUMN notified_at TIMESTAMPTZ NULL;
+-- the alerts stored so far count as sent
+UPDATE alerts SET notified_at = triggered_at;
+CREATE INDEX alerts_unnotified_idx ON alerts (rule) WHERE notified_at IS NULL;